@@ -0,0 +1,28 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterModelSource is a cluster-scoped ModelSource that Models in any namespace can reference.
+// Its secretRef names a Secret in the operator namespace.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=cmsrc
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+type ClusterModelSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ModelSourceSpec   `json:"spec,omitempty"`
+	Status ModelSourceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterModelSourceList is a list of cluster-scoped sources.
+type ClusterModelSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterModelSource `json:"items"`
+}
@@ -0,0 +1,101 @@
+package v1
+
+// Condition types reported on Model status.
+const (
+	// ModelConditionReady is True when every PRESENT version has a Ready Dataset.
+	ModelConditionReady = "Ready"
+	// ModelConditionProgressing is True while any PRESENT version is still syncing.
+	ModelConditionProgressing = "Progressing"
+	// ModelConditionDegraded is True when a Dataset failed, an upstream check failed, sharing failed, or reconciliation errored.
+	ModelConditionDegraded = "Degraded"
+	// ModelConditionUpdateAvailable is True when a tracked version has a newer upstream commit than the one synced.
+	ModelConditionUpdateAvailable = "UpdateAvailable"
+	// ModelConditionRevisionUnresolved is True while a version syncs its revision unpinned because the
+	// revision could not be resolved to a commit. It is only present while a lookup is failing.
+	ModelConditionRevisionUnresolved = "RevisionUnresolved"
+	// ModelConditionStorageDefaulted is True when the PVC of a version without a storage spec was sized
+	// with the default storage size because its upstream files could not be listed. It is only present then.
+	ModelConditionStorageDefaulted = "StorageDefaulted"
+	// ModelConditionShared is True when every namespace matched by a version's share holds its REFERENCE Dataset.
+	// It is only present while at least one version is shared.
+	ModelConditionShared = "Shared"
+	// ModelConditionReconcileError holds the last reconcile error until the next successful reconcile.
+	ModelConditionReconcileError = "ReconcileError"
+	// ModelConditionTerminating is True while a deleted Model waits for its Datasets, references and
+	// copies to be deleted, and lists what is still blocking.
+	ModelConditionTerminating = "Terminating"
+)
+
+// Condition reasons reported on Model status.
+const (
+	ReasonAllVersionsReady  = "AllVersionsReady"
+	ReasonNoVersionsPresent = "NoVersionsPresent"
+	ReasonVersionsNotReady  = "VersionsNotReady"
+	ReasonDatasetsSyncing   = "DatasetsSyncing"
+	ReasonSyncComplete      = "SyncComplete"
+	ReasonDatasetFailed     = "DatasetFailed"
+	ReasonShareFailed       = "ShareFailed"
+	ReasonTargetsShared     = "TargetsShared"
+	ReasonNoShareTargets    = "NoShareTargets"
+	ReasonApprovalPending   = "ApprovalPending"
+	ReasonSharePending      = "SharePending"
+	ReasonShareExpired      = "ShareExpired"
+	ReasonAsExpected        = "AsExpected"
+	ReasonUpstreamUpdated   = "UpstreamUpdated"
+	ReasonUpToDate          = "UpToDate"
+	ReasonPreflightFailed   = "PreflightFailed"
+	ReasonLookupFailed      = "LookupFailed"
+	ReasonListFilesFailed   = "ListFilesFailed"
+	ReasonCleanupPending    = "CleanupPending"
+	ReasonCleanupTimedOut   = "CleanupTimedOut"
+	ReasonCleanupFailed     = "CleanupFailed"
+)
+
+// Preflight reasons recorded on synced versions.
+const (
+	ReasonPreflightPassed  = "PreflightPassed"
+	ReasonRepoNotFound     = "RepoNotFound"
+	ReasonRevisionNotFound = "RevisionNotFound"
+	ReasonAccessGated      = "AccessGated"
+	ReasonUnauthorized     = "Unauthorized"
+	// ReasonCheckUnavailable records a check that could not reach a verdict, e.g. on an upstream
+	// API error. It does not block the version.
+	ReasonCheckUnavailable = "CheckUnavailable"
+)
+
+// Condition types reported on ModelSource and ClusterModelSource status.
+const (
+	// SourceConditionCredentialsReady is True when the referenced Secret holds the credentials the source type requires.
+	SourceConditionCredentialsReady = "CredentialsReady"
+	// SourceConditionReachable is True when the last probe reached the source endpoint.
+	SourceConditionReachable = "Reachable"
+	// SourceConditionAuthenticated is True when the source endpoint accepted the credentials on the last probe.
+	SourceConditionAuthenticated = "Authenticated"
+)
+
+// Condition reasons reported on ModelSource and ClusterModelSource status.
+const (
+	ReasonProbeSucceeded      = "ProbeSucceeded"
+	ReasonProbeFailed         = "ProbeFailed"
+	ReasonEndpointUnreachable = "EndpointUnreachable"
+	ReasonCredentialsRejected = "CredentialsRejected"
+	ReasonAnonymous           = "Anonymous"
+	ReasonNoProbeTarget       = "NoProbeTarget"
+)
+
+// Condition types reported on ModelClaim status.
+const (
+	// ClaimConditionBound is True when the claim's REFERENCE Dataset exists.
+	ClaimConditionBound = "Bound"
+)
+
+// Condition reasons reported on ModelClaim status.
+const (
+	ReasonClaimBound       = "ClaimBound"
+	ReasonModelNotFound    = "ModelNotFound"
+	ReasonVersionNotFound  = "VersionNotFound"
+	ReasonShareNotAllowed  = "ShareNotAllowed"
+	ReasonVersionNotSynced = "VersionNotSynced"
+	ReasonDatasetConflict  = "DatasetConflict"
+	ReasonClaimError       = "ClaimError"
+)
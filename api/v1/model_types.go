@@ -1,17 +1,45 @@
 package v1
 
 import (
+	"encoding/json"
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// SourceKindModelSource is the sourceRef kind of a namespaced ModelSource.
+	SourceKindModelSource = "ModelSource"
+	// SourceKindClusterModelSource is the sourceRef kind of a ClusterModelSource.
+	SourceKindClusterModelSource = "ClusterModelSource"
+)
+
+const (
+	// ResyncAnnotation requests a re-download of every version of a Model.
+	// A new value (typically an RFC3339 timestamp) triggers a new Dataset sync round.
+	ResyncAnnotation = "modelfs.samzong.dev/resyncAt"
+	// VersionResyncAnnotationPrefix requests a re-download of a single version.
+	// The full annotation key is the prefix followed by the version name.
+	VersionResyncAnnotationPrefix = ResyncAnnotation + "."
+	// ShareApprovalAnnotationPrefix approves shares of a version whose share requires Manual approval.
+	// The full annotation key is the prefix followed by the version name; the value is a
+	// comma-separated list of the approved namespaces.
+	ShareApprovalAnnotationPrefix = "modelfs.samzong.dev/approve-share."
+	// ForceReleaseAnnotation set to "true" on a deleted Model removes its finalizer even though its
+	// Datasets, references or copies are not all gone. It is only honored once the cleanup timeout
+	// has passed since the deletion.
+	ForceReleaseAnnotation = "modelfs.samzong.dev/force-release"
+)
+
 // Model describes a machine learning model instance tracked by the system.
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:shortName=model
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 // +kubebuilder:printcolumn:name="Versions",type=integer,JSONPath=`.status.syncedVersions[*].name`
-// +kubebuilder:printcolumn:name="Source",type=string,JSONPath=`.spec.sourceRef`
+// +kubebuilder:printcolumn:name="Source",type=string,JSONPath=`.spec.sourceRef.name`
 type Model struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -23,8 +51,12 @@ type Model struct {
 // ModelSpec contains desired attributes for a model.
 type ModelSpec struct {
 	// +kubebuilder:validation:Required
-	// SourceRef references a ModelSource in the same namespace.
-	SourceRef string `json:"sourceRef"`
+	// SourceRef references a ModelSource in the same namespace or a ClusterModelSource.
+	// The legacy string form, the name of a ModelSource, is accepted as well, so the schema
+	// leaves the field open and the webhook validates it.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	SourceRef SourceReference `json:"sourceRef"`
 	// +kubebuilder:validation:Optional
 	// Display contains display metadata for catalog purposes.
 	Display *DisplaySpec `json:"display,omitempty"`
@@ -32,6 +64,57 @@ type ModelSpec struct {
 	// +kubebuilder:validation:MinItems=1
 	// Versions defines all model versions and their configurations.
 	Versions []ModelVersion `json:"versions"`
+	// +kubebuilder:validation:Optional
+	// DeletionPolicy controls what happens to the downloaded files of a version when it is set
+	// to ABSENT or the Model is deleted (default: Delete). A version can override it.
+	// +kubebuilder:default=Delete
+	// +kubebuilder:validation:Enum=Delete;Retain;Orphan
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// SourceReference identifies the ModelSource or ClusterModelSource of a Model.
+type SourceReference struct {
+	// +kubebuilder:validation:Optional
+	// Kind is the kind of the referenced source (default: ModelSource).
+	// +kubebuilder:default=ModelSource
+	// +kubebuilder:validation:Enum=ModelSource;ClusterModelSource
+	Kind string `json:"kind,omitempty"`
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// Name is the name of the referenced source.
+	Name string `json:"name"`
+}
+
+// UnmarshalJSON accepts the legacy string form of sourceRef, which names a ModelSource.
+func (r *SourceReference) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*r = SourceReference{Kind: SourceKindModelSource, Name: name}
+		return nil
+	}
+	type sourceReference SourceReference
+	return json.Unmarshal(data, (*sourceReference)(r))
+}
+
+// IsCluster reports whether the reference names a ClusterModelSource.
+func (r SourceReference) IsCluster() bool {
+	return r.Kind == SourceKindClusterModelSource
+}
+
+// String returns the name for a ModelSource and "ClusterModelSource/<name>" for a ClusterModelSource.
+func (r SourceReference) String() string {
+	if r.IsCluster() {
+		return SourceKindClusterModelSource + "/" + r.Name
+	}
+	return r.Name
+}
+
+// ParseSourceReference parses the String form of a SourceReference.
+func ParseSourceReference(s string) SourceReference {
+	if name, ok := strings.CutPrefix(s, SourceKindClusterModelSource+"/"); ok {
+		return SourceReference{Kind: SourceKindClusterModelSource, Name: name}
+	}
+	return SourceReference{Kind: SourceKindModelSource, Name: s}
 }
 
 // DisplaySpec contains display metadata for a model.
@@ -59,6 +142,17 @@ type ModelVersion struct {
 	// Revision specifies the revision/branch/tag to use (default: "main").
 	Revision string `json:"revision,omitempty"`
 	// +kubebuilder:validation:Optional
+	// RevisionPolicy controls how a branch or tag revision is used (default: Pin).
+	// Pin resolves the revision to a commit SHA and syncs that commit until the
+	// revision changes or an Auto update policy moves it.
+	// Follow syncs whatever the branch or tag points to at sync time.
+	// +kubebuilder:default=Pin
+	// +kubebuilder:validation:Enum=Pin;Follow
+	RevisionPolicy RevisionPolicy `json:"revisionPolicy,omitempty"`
+	// +kubebuilder:validation:Optional
+	// UpdatePolicy controls whether the controller tracks new upstream commits of the revision.
+	UpdatePolicy *UpdatePolicy `json:"updatePolicy,omitempty"`
+	// +kubebuilder:validation:Optional
 	// +kubebuilder:validation:Enum=FP16;INT4;INT8
 	// Precision specifies the model precision.
 	Precision string `json:"precision,omitempty"`
@@ -76,6 +170,10 @@ type ModelVersion struct {
 	// +kubebuilder:validation:Optional
 	// Share defines sharing configuration for this version.
 	Share *ShareSpec `json:"share,omitempty"`
+	// +kubebuilder:validation:Optional
+	// DeletionPolicy overrides the Model's deletion policy for this version.
+	// +kubebuilder:validation:Enum=Delete;Retain;Orphan
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
 }
 
 // ModelVersionState represents the desired state of a model version.
@@ -89,6 +187,59 @@ const (
 	ModelVersionStateAbsent ModelVersionState = "ABSENT"
 )
 
+// DeletionPolicy controls what happens to the active Dataset of a version and the volume holding
+// its files when the version is set to ABSENT or the Model is deleted. Pending and retiring
+// Datasets are always deleted.
+// +kubebuilder:validation:Enum=Delete;Retain;Orphan
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the Dataset together with its PVC and volume.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyRetain deletes the Dataset but keeps its PersistentVolume, labeled with the
+	// model version, so the version binds to it again when it is re-added.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyOrphan keeps the Dataset and its PVC, detached from the Model, so the
+	// version adopts the Dataset again when it is re-added.
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// RevisionPolicy controls whether a floating revision is pinned to a commit SHA.
+// +kubebuilder:validation:Enum=Pin;Follow
+type RevisionPolicy string
+
+const (
+	// RevisionPolicyPin pins the Dataset to the commit SHA the revision resolved to.
+	RevisionPolicyPin RevisionPolicy = "Pin"
+	// RevisionPolicyFollow passes the revision to the Dataset unresolved.
+	RevisionPolicyFollow RevisionPolicy = "Follow"
+)
+
+// UpdateMode controls what happens when the upstream revision moves to a new commit.
+// +kubebuilder:validation:Enum=Manual;Notify;Auto
+type UpdateMode string
+
+const (
+	// UpdateModeManual never checks the upstream revision after it is first resolved.
+	UpdateModeManual UpdateMode = "Manual"
+	// UpdateModeNotify checks the upstream revision and reports new commits through the UpdateAvailable condition.
+	UpdateModeNotify UpdateMode = "Notify"
+	// UpdateModeAuto checks the upstream revision and re-syncs the version when it moves.
+	UpdateModeAuto UpdateMode = "Auto"
+)
+
+// UpdatePolicy defines how a version tracks its upstream revision.
+type UpdatePolicy struct {
+	// +kubebuilder:validation:Optional
+	// Mode is the update mode (default: Manual).
+	// +kubebuilder:default=Manual
+	// +kubebuilder:validation:Enum=Manual;Notify;Auto
+	Mode UpdateMode `json:"mode,omitempty"`
+	// +kubebuilder:validation:Optional
+	// Interval is how often the upstream revision is checked (default: 1h).
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
 // ModelVolumeSpec defines the PVC specification for a model version.
 type ModelVolumeSpec struct {
 	// +kubebuilder:validation:Optional
@@ -114,8 +265,53 @@ type ShareSpec struct {
 	// RequireOptInLabel specifies a label key-value pair that namespaces must have to receive shares.
 	// Format: "key=value" or just "key" (value defaults to "true").
 	RequireOptInLabel string `json:"requireOptInLabel,omitempty"`
+	// +kubebuilder:validation:Optional
+	// Mode is how the version reaches target namespaces (default: Reference).
+	// Reference creates a REFERENCE Dataset that mounts the volume of the source Dataset.
+	// Copy gives each namespace its own PVC restored from a CSI VolumeSnapshot of the source PVC.
+	// +kubebuilder:default=Reference
+	Mode ShareMode `json:"mode,omitempty"`
+	// +kubebuilder:validation:Optional
+	// VolumeSnapshotClassName is the VolumeSnapshotClass of the snapshots taken in Copy mode.
+	// When empty, the default class of the source PVC's CSI driver is used.
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+	// +kubebuilder:validation:Optional
+	// Approval controls whether the model owner must approve each namespace (default: None).
+	// With Manual, a namespace only receives the share once the Model lists it in the
+	// modelfs.samzong.dev/approve-share.<version> annotation.
+	// +kubebuilder:default=None
+	Approval ShareApprovalMode `json:"approval,omitempty"`
+	// +kubebuilder:validation:Optional
+	// ExpiresAfter limits how long a namespace keeps the share, counted from its approval,
+	// or from when it was first shared if no approval is required.
+	ExpiresAfter *metav1.Duration `json:"expiresAfter,omitempty"`
+	// +kubebuilder:validation:Optional
+	// ExpiresAt ends the share for all namespaces at a fixed time.
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
 }
 
+// ShareMode is how a shared version reaches target namespaces.
+// +kubebuilder:validation:Enum=Reference;Copy
+type ShareMode string
+
+const (
+	// ShareModeReference creates a REFERENCE Dataset to the source Dataset in each namespace.
+	ShareModeReference ShareMode = "Reference"
+	// ShareModeCopy restores a private PVC in each namespace from a snapshot of the source PVC.
+	ShareModeCopy ShareMode = "Copy"
+)
+
+// ShareApprovalMode controls whether shares need the model owner's approval.
+// +kubebuilder:validation:Enum=None;Manual
+type ShareApprovalMode string
+
+const (
+	// ShareApprovalNone shares into every matching namespace.
+	ShareApprovalNone ShareApprovalMode = "None"
+	// ShareApprovalManual shares only into matching namespaces the model owner approved.
+	ShareApprovalManual ShareApprovalMode = "Manual"
+)
+
 // ModelStatus captures observed details about a model.
 type ModelStatus struct {
 	// +kubebuilder:validation:Optional
@@ -126,6 +322,9 @@ type ModelStatus struct {
 	// +kubebuilder:validation:Optional
 	// ObservedGeneration tracks the generation of the Model spec that was last reconciled.
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +kubebuilder:validation:Optional
+	// ObservedSecretHash is the source secretHash the Datasets were last synced with.
+	ObservedSecretHash string `json:"observedSecretHash,omitempty"`
 }
 
 // SyncedVersion represents the observed state of a model version.
@@ -138,6 +337,10 @@ type SyncedVersion struct {
 	PVCName string `json:"pvcName,omitempty"`
 	// ActiveDataset is the name of the currently active Dataset CR.
 	ActiveDataset string `json:"activeDataset,omitempty"`
+	// PendingDataset is the Dataset syncing a changed version spec; it replaces ActiveDataset once Ready.
+	PendingDataset string `json:"pendingDataset,omitempty"`
+	// RetiringDatasets are previously active Datasets kept until their grace period ends.
+	RetiringDatasets []RetiringDataset `json:"retiringDatasets,omitempty"`
 	// LastSyncTime is the last sync time from the Dataset status.
 	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
 	// Conditions contains conditions from the Dataset status.
@@ -146,8 +349,138 @@ type SyncedVersion struct {
 	ObservedState ModelVersionState `json:"observedState,omitempty"`
 	// ObservedStorage is the observed storage capacity.
 	ObservedStorage *resource.Quantity `json:"observedStorage,omitempty"`
-	// ObservedVersionHash is a hash of the version spec for change detection.
+	// EstimatedSize is the size of the upstream files the sync downloads, used to size the PVC
+	// when the version has no storage spec.
+	EstimatedSize *resource.Quantity `json:"estimatedSize,omitempty"`
+	// EstimatedFrom is the "repo@revision" that EstimatedSize was computed for.
+	EstimatedFrom string `json:"estimatedFrom,omitempty"`
+	// EstimateError is why the upstream files could not be listed, in which case the PVC
+	// was sized with the default storage size.
+	EstimateError string `json:"estimateError,omitempty"`
+	// ObservedVersionHash is a hash of the version spec the active Dataset was built from.
 	ObservedVersionHash string `json:"observedVersionHash,omitempty"`
+	// ResolvedRevision is the commit SHA the Dataset is synced from.
+	ResolvedRevision string `json:"resolvedRevision,omitempty"`
+	// ResolvedFrom is the "repo@revision" that ResolvedRevision was resolved from, or that
+	// ResolveError failed to resolve.
+	ResolvedFrom string `json:"resolvedFrom,omitempty"`
+	// LatestRevision is the commit SHA the revision pointed to at the last upstream check.
+	LatestRevision string `json:"latestRevision,omitempty"`
+	// LastUpdateCheck is the time of the last upstream check.
+	LastUpdateCheck *metav1.Time `json:"lastUpdateCheck,omitempty"`
+	// ResolveError is why the revision could not be resolved to a commit. Until a retry
+	// succeeds the version syncs the revision as written.
+	ResolveError string `json:"resolveError,omitempty"`
+	// Preflight is the result of the last upstream check of the version's repo and revision.
+	Preflight *PreflightResult `json:"preflight,omitempty"`
+	// LastResyncRequest is the most recent resync request observed for this version.
+	LastResyncRequest string `json:"lastResyncRequest,omitempty"`
+	// LastResyncHandled is the most recent resync request that triggered a new Dataset sync round.
+	LastResyncHandled string `json:"lastResyncHandled,omitempty"`
+	// ShareTargets are the namespaces the version is shared into.
+	// +listType=map
+	// +listMapKey=namespace
+	ShareTargets []ShareTarget `json:"shareTargets,omitempty"`
+	// ShareHistory records the most recent approvals, revocations and expiries of shares, oldest first.
+	ShareHistory []ShareEvent `json:"shareHistory,omitempty"`
+	// ShareSnapshot is the VolumeSnapshot of the active Dataset's PVC that Copy shares are restored from.
+	ShareSnapshot *ShareSnapshot `json:"shareSnapshot,omitempty"`
+	// RetainedVolume is the PersistentVolume the Retain deletion policy kept when the version was set
+	// to ABSENT; the version binds to it again when it is set back to PRESENT.
+	RetainedVolume string `json:"retainedVolume,omitempty"`
+	// OrphanedDataset is the Dataset the Orphan deletion policy detached when the version was set
+	// to ABSENT; the version adopts it again when it is set back to PRESENT.
+	OrphanedDataset string `json:"orphanedDataset,omitempty"`
+}
+
+// ShareSnapshot records the state of the VolumeSnapshot behind Copy shares.
+type ShareSnapshot struct {
+	// Name is the VolumeSnapshot name in the model namespace.
+	Name string `json:"name"`
+	// SourceDataset is the Dataset whose PVC was snapshotted.
+	SourceDataset string `json:"sourceDataset"`
+	// ReadyToUse is true once copies can be restored from the snapshot.
+	ReadyToUse bool `json:"readyToUse"`
+	// Error is the last error of the snapshot.
+	Error string `json:"error,omitempty"`
+}
+
+// ShareTarget records a namespace a version is shared into.
+type ShareTarget struct {
+	// Namespace is the target namespace.
+	Namespace string `json:"namespace"`
+	// Dataset is the name of the REFERENCE Dataset in the target namespace.
+	Dataset string `json:"dataset,omitempty"`
+	// PVCName is the name of the PVC restored in the target namespace in Copy mode.
+	PVCName string `json:"pvcName,omitempty"`
+	// Phase is the phase of the REFERENCE Dataset, or of the PVC in Copy mode.
+	Phase string `json:"phase,omitempty"`
+	// LastError is the error of the last attempt to create or update the REFERENCE Dataset.
+	LastError string `json:"lastError,omitempty"`
+	// State is Shared, SharePending, PendingApproval or Expired. Only Shared targets are sure to hold
+	// a REFERENCE Dataset to the active Dataset.
+	State ShareTargetState `json:"state,omitempty"`
+	// GrantedAt is when the share was approved, or first made if no approval is required.
+	GrantedAt *metav1.Time `json:"grantedAt,omitempty"`
+	// ExpiresAt is when the share expires.
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// ShareTargetState is the state of a share into a namespace.
+type ShareTargetState string
+
+const (
+	// ShareTargetShared means the namespace holds a REFERENCE Dataset.
+	ShareTargetShared ShareTargetState = "Shared"
+	// ShareTargetSharePending means the share is granted but waits for the source Dataset or its
+	// snapshot to be Ready, or for the reference or copy of a replaced source to be deleted.
+	ShareTargetSharePending ShareTargetState = "SharePending"
+	// ShareTargetPendingApproval means the namespace matches but the model owner has not approved it.
+	ShareTargetPendingApproval ShareTargetState = "PendingApproval"
+	// ShareTargetExpired means the share expired and its REFERENCE Dataset was deleted.
+	ShareTargetExpired ShareTargetState = "Expired"
+)
+
+// ShareEvent records a change of a share's approval.
+type ShareEvent struct {
+	// Namespace is the target namespace.
+	Namespace string `json:"namespace"`
+	// Type is Approved, Revoked or Expired.
+	Type ShareEventType `json:"type"`
+	// Time is when the controller observed the change.
+	Time metav1.Time `json:"time"`
+}
+
+// ShareEventType is the kind of a ShareEvent.
+type ShareEventType string
+
+const (
+	// ShareEventApproved records that the model owner approved a namespace.
+	ShareEventApproved ShareEventType = "Approved"
+	// ShareEventRevoked records that the model owner withdrew the approval of a namespace.
+	ShareEventRevoked ShareEventType = "Revoked"
+	// ShareEventExpired records that a share expired.
+	ShareEventExpired ShareEventType = "Expired"
+)
+
+// PreflightResult records the upstream check made before a Dataset is created for a version.
+type PreflightResult struct {
+	// From is the "repo@revision" that was checked.
+	From string `json:"from"`
+	// Reason is PreflightPassed, RepoNotFound, RevisionNotFound, AccessGated, Unauthorized or CheckUnavailable.
+	Reason string `json:"reason"`
+	// Message details the result.
+	Message string `json:"message,omitempty"`
+	// CheckedAt is when the check ran.
+	CheckedAt metav1.Time `json:"checkedAt"`
+}
+
+// RetiringDataset is a replaced Dataset awaiting garbage collection.
+type RetiringDataset struct {
+	// Name is the Dataset name.
+	Name string `json:"name"`
+	// RetiredAt is when the Dataset stopped being active.
+	RetiredAt metav1.Time `json:"retiredAt"`
 }
 
 // +kubebuilder:object:root=true
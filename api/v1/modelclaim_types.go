@@ -0,0 +1,81 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ModelClaim requests a shared model version in the claim's namespace. The controller fulfills
+// it with a REFERENCE Dataset named after the claim when the version's share allows the namespace.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=mclaim
+// +kubebuilder:printcolumn:name="Model",type=string,JSONPath=`.spec.model`
+// +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.spec.version`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Dataset",type=string,JSONPath=`.status.dataset`
+type ModelClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ModelClaimSpec   `json:"spec,omitempty"`
+	Status ModelClaimStatus `json:"status,omitempty"`
+}
+
+// ModelClaimSpec names the model version a claim requests.
+type ModelClaimSpec struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?/[a-z0-9]([-.a-z0-9]*[a-z0-9])?$`
+	// Model is the Model to claim in "namespace/name" format.
+	Model string `json:"model"`
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// Version is the name of the claimed version.
+	Version string `json:"version"`
+}
+
+// ModelClaimPhase is the lifecycle phase of a claim.
+// +kubebuilder:validation:Enum=Pending;Bound;Denied
+type ModelClaimPhase string
+
+const (
+	// ModelClaimPhasePending means the claimed version has no Dataset to reference yet, or the
+	// share awaits the model owner's approval.
+	ModelClaimPhasePending ModelClaimPhase = "Pending"
+	// ModelClaimPhaseBound means the claim's REFERENCE Dataset exists.
+	ModelClaimPhaseBound ModelClaimPhase = "Bound"
+	// ModelClaimPhaseDenied means the model or version does not exist, does not share into the
+	// namespace, or the share expired.
+	ModelClaimPhaseDenied ModelClaimPhase = "Denied"
+)
+
+// ModelClaimStatus reports whether a claim is fulfilled.
+type ModelClaimStatus struct {
+	// +kubebuilder:validation:Optional
+	// Phase is Pending, Bound or Denied.
+	Phase ModelClaimPhase `json:"phase,omitempty"`
+	// +kubebuilder:validation:Optional
+	// Dataset is the name of the REFERENCE Dataset fulfilling the claim.
+	Dataset string `json:"dataset,omitempty"`
+	// +kubebuilder:validation:Optional
+	// GrantedAt is when the share was approved, or first granted if no approval is required.
+	GrantedAt *metav1.Time `json:"grantedAt,omitempty"`
+	// +kubebuilder:validation:Optional
+	// ExpiresAt is when the share expires.
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+	// +kubebuilder:validation:Optional
+	// ObservedGeneration is the claim generation the status reflects.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ModelClaimList is a list of claims.
+type ModelClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelClaim `json:"items"`
+}
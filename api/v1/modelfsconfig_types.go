@@ -0,0 +1,88 @@
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ModelfsConfigName is the name of the ModelfsConfig the operator reads; others are ignored.
+const ModelfsConfigName = "default"
+
+// ModelfsConfig holds operator-wide defaults, optionally overridden per namespace.
+// Changes are picked up without restarting the operator.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=mfcfg
+// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'default'",message="the ModelfsConfig must be named 'default'"
+type ModelfsConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ModelfsConfigSpec   `json:"spec,omitempty"`
+	Status ModelfsConfigStatus `json:"status,omitempty"`
+}
+
+// ModelfsConfigSpec describes the defaults applied by the operator.
+type ModelfsConfigSpec struct {
+	// +kubebuilder:validation:Optional
+	// Defaults apply to all namespaces.
+	Defaults ModelfsDefaults `json:"defaults,omitempty"`
+	// +kubebuilder:validation:Optional
+	// Namespaces override the defaults for individual namespaces.
+	// +listType=map
+	// +listMapKey=namespace
+	Namespaces []NamespaceDefaults `json:"namespaces,omitempty"`
+}
+
+// ModelfsDefaults are settings used when a Model or version does not set them. Empty fields
+// fall back to the next level: namespace, global, then the operator's built-in value.
+type ModelfsDefaults struct {
+	// +kubebuilder:validation:Optional
+	// StorageSize is the PVC size of a version without storage when the upstream size cannot be estimated.
+	StorageSize *resource.Quantity `json:"storageSize,omitempty"`
+	// +kubebuilder:validation:Optional
+	// AccessModes are the PVC access modes of a version without storage.
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	// +kubebuilder:validation:Optional
+	// Revision is the revision of a version that does not set one.
+	Revision string `json:"revision,omitempty"`
+	// +kubebuilder:validation:Optional
+	// ErrorRequeueInterval is how long a Model waits after a reconcile error before it is retried.
+	ErrorRequeueInterval *metav1.Duration `json:"errorRequeueInterval,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*-)?$`
+	// +kubebuilder:validation:MaxLength=16
+	// DatasetNamePrefix prefixes the names of the Datasets created for versions.
+	DatasetNamePrefix string `json:"datasetNamePrefix,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*-)?$`
+	// +kubebuilder:validation:MaxLength=16
+	// ShareNamePrefix prefixes the names of the REFERENCE Datasets created for shares.
+	ShareNamePrefix string `json:"shareNamePrefix,omitempty"`
+}
+
+// NamespaceDefaults overrides the defaults for one namespace.
+type NamespaceDefaults struct {
+	// +kubebuilder:validation:Required
+	// Namespace is the namespace the defaults apply to.
+	Namespace string `json:"namespace"`
+	// +kubebuilder:validation:Optional
+	ModelfsDefaults `json:",inline"`
+}
+
+// ModelfsConfigStatus reports whether the operator loaded the config.
+type ModelfsConfigStatus struct {
+	// +kubebuilder:validation:Optional
+	// ObservedGeneration is the generation of the spec the operator currently applies.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ModelfsConfigList is a list of ModelfsConfigs.
+type ModelfsConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelfsConfig `json:"items"`
+}
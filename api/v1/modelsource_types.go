@@ -27,11 +27,34 @@ type ModelSourceSpec struct {
 	// Optional for public models (e.g., HuggingFace public repos).
 	SecretRef string `json:"secretRef,omitempty"`
 	// +kubebuilder:validation:Optional
+	// SecretKeys maps keys of the Secret to the Dataset option names they are passed as,
+	// e.g. {"hf_token": "token"}. Only the listed keys are used.
+	// When empty, the credential keys known for the source type are used.
+	SecretKeys map[string]string `json:"secretKeys,omitempty"`
+	// +kubebuilder:validation:Optional
+	// SecretMode controls how credentials reach the Dataset (default: Embed).
+	// Embed copies the mapped keys into the Dataset options.
+	// Reference points the Dataset at a Secret so values never appear in the Dataset.
+	// +kubebuilder:default=Embed
+	// +kubebuilder:validation:Enum=Embed;Reference
+	SecretMode SecretMode `json:"secretMode,omitempty"`
+	// +kubebuilder:validation:Optional
 	// +kubebuilder:pruning:PreserveUnknownFields
 	// Config contains source-specific configuration. Keys must be supported by the DatasetType.
 	Config map[string]string `json:"config,omitempty"`
 }
 
+// SecretMode controls how source credentials are handed to Datasets.
+// +kubebuilder:validation:Enum=Embed;Reference
+type SecretMode string
+
+const (
+	// SecretModeEmbed copies the mapped Secret keys into the Dataset options.
+	SecretModeEmbed SecretMode = "Embed"
+	// SecretModeReference sets the Dataset secretRef instead of copying values.
+	SecretModeReference SecretMode = "Reference"
+)
+
 // ModelSourceStatus tracks availability of a source.
 type ModelSourceStatus struct {
 	// +kubebuilder:validation:Optional
@@ -39,6 +62,15 @@ type ModelSourceStatus struct {
 	// +kubebuilder:validation:Optional
 	// ReferencedBy lists Model names that reference this ModelSource (namespace/name format).
 	ReferencedBy []string `json:"referencedBy,omitempty"`
+	// +kubebuilder:validation:Optional
+	// SecretHash is a hash of the credentials read from the Secret. It changes when the Secret is rotated.
+	SecretHash string `json:"secretHash,omitempty"`
+	// +kubebuilder:validation:Optional
+	// LastProbeTime is when the source endpoint was last probed.
+	LastProbeTime *metav1.Time `json:"lastProbeTime,omitempty"`
+	// +kubebuilder:validation:Optional
+	// ProbeLatency is how long the last probe took.
+	ProbeLatency *metav1.Duration `json:"probeLatency,omitempty"`
 }
 
 // +kubebuilder:object:root=true
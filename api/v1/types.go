@@ -16,4 +16,7 @@ var (
 func init() {
 	SchemeBuilder.Register(&Model{}, &ModelList{})
 	SchemeBuilder.Register(&ModelSource{}, &ModelSourceList{})
+	SchemeBuilder.Register(&ClusterModelSource{}, &ClusterModelSourceList{})
+	SchemeBuilder.Register(&ModelfsConfig{}, &ModelfsConfigList{})
+	SchemeBuilder.Register(&ModelClaim{}, &ModelClaimList{})
 }
@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	"github.com/samzong/modelfs/pkg/upstream"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ClusterModelSourceReconciler reconciles a ClusterModelSource object
+type ClusterModelSourceReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// OperatorNamespace holds the Secrets referenced by ClusterModelSources.
+	OperatorNamespace string
+	// Probers check the source endpoint and credentials per source type. Sources are not probed if nil.
+	Probers upstream.Probers
+	// ProbeInterval is how often a source is probed. Defaults to DefaultSourceProbeInterval.
+	ProbeInterval time.Duration
+}
+
+//+kubebuilder:rbac:groups=model.samzong.dev,resources=clustermodelsources,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=model.samzong.dev,resources=clustermodelsources/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=model.samzong.dev,resources=clustermodelsources/finalizers,verbs=update
+//+kubebuilder:rbac:groups=model.samzong.dev,resources=models,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop.
+func (r *ClusterModelSourceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	source := &modelv1.ClusterModelSource{}
+	if err := r.Get(ctx, req.NamespacedName, source); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Handle deletion
+	if !source.DeletionTimestamp.IsZero() {
+		return r.handleDeletion(ctx, source)
+	}
+
+	// Ensure finalizer
+	if err := r.ensureFinalizer(ctx, source); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	status := source.Status.DeepCopy()
+
+	// Validate Secret in the operator namespace (optional for some source types)
+	var secretErr error
+	var creds upstream.Credentials
+	if source.Spec.SecretRef != "" {
+		var mapped map[string][]byte
+		mapped, secretErr = validateSourceSecret(ctx, r.Client, r.operatorNamespace(), source.Spec)
+		if secretErr != nil {
+			status.SecretHash = ""
+			setCredentialsCondition(status, source.Generation, false, "SecretInvalid", secretErr.Error())
+		} else {
+			// Record the hash of the credentials so dependent Models notice a rotation
+			status.SecretHash = secretDataHash(mapped)
+			creds = upstream.CredentialsFromSecretData(mapped)
+			setCredentialsCondition(status, source.Generation, true, "SecretValid",
+				fmt.Sprintf("Secret %s/%s is valid and accessible", r.operatorNamespace(), source.Spec.SecretRef))
+		}
+	} else {
+		// No secret required (e.g., public HuggingFace models)
+		status.SecretHash = ""
+		setCredentialsCondition(status, source.Generation, true, "NoSecretRequired", "No secret required for this source type")
+	}
+
+	// Probe the endpoint once the credentials are usable
+	var nextProbe time.Duration
+	if secretErr == nil {
+		nextProbe = probeSourceIfDue(ctx, r.Probers, r.ProbeInterval, source.Spec, creds, status,
+			source.Generation, source.Status.SecretHash != status.SecretHash)
+	}
+
+	// Find referencing Models across all namespaces
+	models, err := r.findReferencingModels(ctx, source)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("find referencing models: %w", err)
+	}
+	status.ReferencedBy = referencedByNames(models)
+
+	source.Status = *status
+	if err := r.Status().Update(ctx, source); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: nextProbe}, secretErr
+}
+
+func (r *ClusterModelSourceReconciler) handleDeletion(ctx context.Context, source *modelv1.ClusterModelSource) (ctrl.Result, error) {
+	// Check if any Model in any namespace references this ClusterModelSource
+	models, err := r.findReferencingModels(ctx, source)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("find referencing models: %w", err)
+	}
+
+	if len(models) > 0 {
+		// Block deletion: update status with referencedBy
+		status := source.Status.DeepCopy()
+		status.ReferencedBy = referencedByNames(models)
+		source.Status = *status
+		if err := r.Status().Update(ctx, source); err != nil {
+			return ctrl.Result{}, err
+		}
+		// Requeue to retry deletion later
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// No references, remove finalizer
+	if containsString(source.Finalizers, ModelSourceFinalizer) {
+		source.Finalizers = removeString(source.Finalizers, ModelSourceFinalizer)
+		if err := r.Update(ctx, source); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *ClusterModelSourceReconciler) ensureFinalizer(ctx context.Context, source *modelv1.ClusterModelSource) error {
+	if !containsString(source.Finalizers, ModelSourceFinalizer) {
+		source.Finalizers = append(source.Finalizers, ModelSourceFinalizer)
+		return r.Update(ctx, source)
+	}
+	return nil
+}
+
+func (r *ClusterModelSourceReconciler) findReferencingModels(ctx context.Context, source *modelv1.ClusterModelSource) ([]modelv1.Model, error) {
+	modelList := &modelv1.ModelList{}
+	if err := r.List(ctx, modelList,
+		client.MatchingFields{ModelSourceRefIndex: sourceRefIndexValue(modelv1.SourceKindClusterModelSource, source.Name)}); err != nil {
+		return nil, err
+	}
+	return modelList.Items, nil
+}
+
+func (r *ClusterModelSourceReconciler) operatorNamespace() string {
+	if r.OperatorNamespace == "" {
+		return DefaultOperatorNamespace
+	}
+	return r.OperatorNamespace
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterModelSourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&modelv1.ClusterModelSource{}).
+		// Keep referencedBy current as Models in any namespace start or stop referencing a source
+		Watches(
+			&modelv1.Model{},
+			handler.EnqueueRequestsFromMapFunc(r.mapModelToClusterModelSource),
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.mapSecretToClusterModelSource),
+			builder.WithPredicates(sourceSecretPredicate()),
+		).
+		Complete(r)
+}
+
+func (r *ClusterModelSourceReconciler) mapSecretToClusterModelSource(ctx context.Context, obj client.Object) []reconcile.Request {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok || secret.Namespace != r.operatorNamespace() {
+		return []reconcile.Request{}
+	}
+
+	// Find all ClusterModelSources that reference the Secret
+	sourceList := &modelv1.ClusterModelSourceList{}
+	if err := r.List(ctx, sourceList, client.MatchingFields{SourceSecretRefIndex: secret.Name}); err != nil {
+		return []reconcile.Request{}
+	}
+
+	requests := make([]reconcile.Request, 0, len(sourceList.Items))
+	for _, source := range sourceList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: source.Name},
+		})
+	}
+
+	return requests
+}
+
+func (r *ClusterModelSourceReconciler) mapModelToClusterModelSource(ctx context.Context, obj client.Object) []reconcile.Request {
+	model, ok := obj.(*modelv1.Model)
+	if !ok || !model.Spec.SourceRef.IsCluster() {
+		return []reconcile.Request{}
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: model.Spec.SourceRef.Name}},
+	}
+}
+
+// setCredentialsCondition sets the CredentialsReady condition of a source status.
+func setCredentialsCondition(status *modelv1.ModelSourceStatus, generation int64, ready bool, reason, message string) {
+	condition := metav1.Condition{
+		Type:               "CredentialsReady",
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: generation,
+	}
+	if ready {
+		condition.Status = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+// referencedByNames formats Models as "namespace/name" for status.referencedBy.
+func referencedByNames(models []modelv1.Model) []string {
+	referencedBy := make([]string, 0, len(models))
+	for _, m := range models {
+		referencedBy = append(referencedBy, formatNamespacedName(m.Namespace, m.Name))
+	}
+	return referencedBy
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	datasetv1alpha1 "github.com/BaizeAI/dataset/api/dataset/v1alpha1"
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	"github.com/samzong/modelfs/pkg/dataset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ModelSourceRefIndex indexes Models by the String form of spec.sourceRef.
+	ModelSourceRefIndex = "spec.sourceRef"
+	// ModelShareEnabledIndex indexes Models that share at least one version under "true".
+	ModelShareEnabledIndex = "spec.versions.share.enabled"
+	// DatasetModelIndex indexes Datasets by the "namespace/name" of the Model they belong to,
+	// taken from the model annotations or, for Datasets created before those, the controlling Model.
+	DatasetModelIndex = "metadata.model"
+	// ModelClaimModelIndex indexes ModelClaims by spec.model, the "namespace/name" of the claimed Model.
+	ModelClaimModelIndex = "spec.model"
+	// SourceSecretRefIndex indexes ModelSources and ClusterModelSources by spec.secretRef.
+	SourceSecretRefIndex = "spec.secretRef"
+)
+
+// SetupIndexes registers the cache indexes the reconcilers and watch mappings look up
+// Models and Datasets with. It must be called once, before the reconcilers are set up.
+func SetupIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &modelv1.Model{}, ModelSourceRefIndex, func(obj client.Object) []string {
+		model := obj.(*modelv1.Model)
+		return []string{model.Spec.SourceRef.String()}
+	}); err != nil {
+		return fmt.Errorf("index field %s: %w", ModelSourceRefIndex, err)
+	}
+
+	if err := indexer.IndexField(ctx, &modelv1.Model{}, ModelShareEnabledIndex, func(obj client.Object) []string {
+		model := obj.(*modelv1.Model)
+		for _, version := range model.Spec.Versions {
+			if version.Share != nil && version.Share.Enabled {
+				return []string{"true"}
+			}
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("index field %s: %w", ModelShareEnabledIndex, err)
+	}
+
+	if err := indexer.IndexField(ctx, &datasetv1alpha1.Dataset{}, DatasetModelIndex, func(obj client.Object) []string {
+		var keys []string
+		if namespace, name, ok := dataset.ModelOf(obj); ok {
+			keys = append(keys, formatNamespacedName(namespace, name))
+		}
+		if owner := metav1.GetControllerOf(obj); owner != nil && owner.Kind == "Model" && owner.APIVersion == modelv1.GroupVersion.String() {
+			if key := formatNamespacedName(obj.GetNamespace(), owner.Name); len(keys) == 0 || keys[0] != key {
+				keys = append(keys, key)
+			}
+		}
+		return keys
+	}); err != nil {
+		return fmt.Errorf("index field %s: %w", DatasetModelIndex, err)
+	}
+
+	if err := indexer.IndexField(ctx, &modelv1.ModelClaim{}, ModelClaimModelIndex, func(obj client.Object) []string {
+		return []string{obj.(*modelv1.ModelClaim).Spec.Model}
+	}); err != nil {
+		return fmt.Errorf("index field %s: %w", ModelClaimModelIndex, err)
+	}
+
+	if err := indexer.IndexField(ctx, &modelv1.ModelSource{}, SourceSecretRefIndex, func(obj client.Object) []string {
+		return secretRefIndexValues(obj.(*modelv1.ModelSource).Spec)
+	}); err != nil {
+		return fmt.Errorf("index field %s on ModelSource: %w", SourceSecretRefIndex, err)
+	}
+
+	if err := indexer.IndexField(ctx, &modelv1.ClusterModelSource{}, SourceSecretRefIndex, func(obj client.Object) []string {
+		return secretRefIndexValues(obj.(*modelv1.ClusterModelSource).Spec)
+	}); err != nil {
+		return fmt.Errorf("index field %s on ClusterModelSource: %w", SourceSecretRefIndex, err)
+	}
+	return nil
+}
+
+// secretRefIndexValues returns the SourceSecretRefIndex values of a source spec.
+func secretRefIndexValues(spec modelv1.ModelSourceSpec) []string {
+	if spec.SecretRef == "" {
+		return nil
+	}
+	return []string{spec.SecretRef}
+}
+
+// sourceRefIndexValue returns the ModelSourceRefIndex value of Models referencing a source.
+func sourceRefIndexValue(kind, name string) string {
+	return modelv1.SourceReference{Kind: kind, Name: name}.String()
+}
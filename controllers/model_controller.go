@@ -4,41 +4,81 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	goerrors "errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	datasetv1alpha1 "github.com/BaizeAI/dataset/api/dataset/v1alpha1"
 	modelv1 "github.com/samzong/modelfs/api/v1"
+	"github.com/samzong/modelfs/pkg/config"
 	"github.com/samzong/modelfs/pkg/dataset"
+	"github.com/samzong/modelfs/pkg/upstream"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
 	ModelFinalizer = "modelfs.samzong.dev/model-finalizer"
+
+	// DefaultUpdateCheckInterval is used when an update policy does not set an interval.
+	DefaultUpdateCheckInterval = time.Hour
+	// RevisionRetryInterval is how often a revision that could not be resolved is looked up again.
+	RevisionRetryInterval = 5 * time.Minute
 )
 
 // ModelReconciler reconciles a Model object
 type ModelReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Resolver pins floating revisions to commit SHAs. Revisions are used as-is when nil.
+	Resolver upstream.Resolver
+	// RetiredDatasetGracePeriod is how long a replaced Dataset is kept before deletion.
+	RetiredDatasetGracePeriod time.Duration
+	// OperatorNamespace holds the Secrets of ClusterModelSources.
+	OperatorNamespace string
+	// Preflighter checks a version's repo and revision before its Dataset is created. Versions are not checked when nil.
+	Preflighter upstream.Preflighter
+	// Sizer lists upstream files to size the PVC of versions without a storage spec. The default size is used when nil.
+	Sizer upstream.Sizer
+	// StorageHeadroomPercent is added to the estimated size. A negative value selects DefaultStorageHeadroomPercent.
+	StorageHeadroomPercent int
+	// Config supplies the defaults from the ModelfsConfig. Built-in defaults are used when nil.
+	Config *config.Store
+	// DeletionCleanupTimeout is how long a deleted Model waits for its cleanup before the force-release
+	// annotation is honored. A zero value selects DefaultDeletionCleanupTimeout.
+	DeletionCleanupTimeout time.Duration
+	// APIReader reads from the API server what the cache cannot be trusted with: the uncached
+	// VolumeSnapshots and restored PVCs of Copy shares, and the Datasets a deleted Model still waits
+	// for. The Client is used when nil.
+	APIReader client.Reader
 }
 
 //+kubebuilder:rbac:groups=model.samzong.dev,resources=models,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=model.samzong.dev,resources=models/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=model.samzong.dev,resources=models/finalizers,verbs=update
 //+kubebuilder:rbac:groups=model.samzong.dev,resources=modelsources,verbs=get;list;watch
+//+kubebuilder:rbac:groups=model.samzong.dev,resources=clustermodelsources,verbs=get;list;watch
 //+kubebuilder:rbac:groups=dataset.baizeai.io,resources=datasets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
-//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups="",resources=persistentvolumes,verbs=get;list;watch;patch;delete
+//+kubebuilder:rbac:groups="",resources=events,verbs=get;list
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots;volumesnapshotcontents,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop.
 func (r *ModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -57,69 +97,113 @@ func (r *ModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, err
 	}
 
-	// Get ModelSource
-	source := &modelv1.ModelSource{}
-	sourceKey := types.NamespacedName{Namespace: model.Namespace, Name: model.Spec.SourceRef}
-	if err := r.Get(ctx, sourceKey, source); err != nil {
-		return r.updateStatusWithError(ctx, model, "ModelSourceNotFound", fmt.Errorf("get modelsource: %w", err))
+	// Status is collected in memory and written once at the end
+	original := model.DeepCopy()
+	result, err := r.reconcileModel(ctx, model)
+	if patchErr := r.patchStatus(ctx, original, model); patchErr != nil {
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("update status: %w (original error: %v)", patchErr, err)
+		}
+		return ctrl.Result{}, fmt.Errorf("update status: %w", patchErr)
 	}
+	return result, err
+}
 
-	// Check if ModelSource credentials are ready (if secret is required)
+// reconcileModel reconciles the Datasets of a Model and computes its status, without writing the status.
+func (r *ModelReconciler) reconcileModel(ctx context.Context, model *modelv1.Model) (ctrl.Result, error) {
+	// Get ModelSource or ClusterModelSource
+	source, err := getModelSource(ctx, r.Client, model, r.operatorNamespace())
+	if err != nil {
+		return r.setErrorStatus(ctx, model, "ModelSourceNotFound", err)
+	}
+
+	// Check if source credentials are ready (if secret is required)
 	if source.Spec.SecretRef != "" && !r.isCredentialsReady(source) {
-		return r.updateStatusWithError(ctx, model, "ModelSourceNotReady", fmt.Errorf("modelsource %s credentials not ready", source.Name))
+		return r.setErrorStatus(ctx, model, "ModelSourceNotReady", fmt.Errorf("%s %s credentials not ready", source.Ref.Kind, source.Ref.Name))
 	}
 
-	// Reconcile versions
+	// Reconcile versions; rotated credentials are propagated into the Datasets on the way, and
+	// only recorded as observed once every version took them
 	if err := r.reconcileVersions(ctx, model, source); err != nil {
-		return ctrl.Result{}, fmt.Errorf("reconcile versions: %w", err)
+		return r.setErrorStatus(ctx, model, "ReconcileVersionsFailed", fmt.Errorf("reconcile versions: %w", err))
 	}
+	model.Status.ObservedSecretHash = source.Status.SecretHash
+
+	// Handle sharing; a failure is reported through the Degraded condition
+	shareErr := r.reconcileSharing(ctx, model)
 
 	// Sync status from Datasets
-	if err := r.syncStatus(ctx, model); err != nil {
+	if err := r.syncStatus(ctx, model, shareErr); err != nil {
 		return ctrl.Result{}, fmt.Errorf("sync status: %w", err)
 	}
 
-	// Handle sharing
-	if err := r.reconcileSharing(ctx, model); err != nil {
-		return ctrl.Result{}, fmt.Errorf("reconcile sharing: %w", err)
+	if shareErr != nil {
+		return ctrl.Result{}, fmt.Errorf("reconcile sharing: %w", shareErr)
 	}
 
-	return ctrl.Result{}, nil
+	// Come back when the next upstream update check, preflight retry, retired Dataset deletion or share expiry is due,
+	// or to poll the snapshots of Copy shares
+	now := time.Now()
+	requeue := minRequeue(nextUpdateCheck(model, now), r.nextRetiredDatasetGC(model, now))
+	requeue = minRequeue(requeue, nextRevisionRetry(model, now))
+	requeue = minRequeue(requeue, minRequeue(nextShareExpiry(model, now), nextShareSnapshotPoll(model)))
+	return ctrl.Result{RequeueAfter: minRequeue(requeue, nextPreflightRetry(model, now))}, nil
 }
 
+// handleDeletion keeps the finalizer of a deleted Model until its cleanup is done, reporting what
+// still blocks it in the Terminating condition. Once the cleanup timeout has passed since the
+// deletion, the ForceReleaseAnnotation releases the Model with the blockers left in place.
 func (r *ModelReconciler) handleDeletion(ctx context.Context, model *modelv1.Model) (ctrl.Result, error) {
 	if !containsString(model.Finalizers, ModelFinalizer) {
 		return ctrl.Result{}, nil
 	}
 
-	// Delete all reference Datasets (shared Datasets in other namespaces)
-	if err := r.deleteReferenceDatasets(ctx, model); err != nil {
-		return ctrl.Result{}, fmt.Errorf("delete reference datasets: %w", err)
+	original := model.DeepCopy()
+	blockers, cleanupErr := r.cleanupModel(ctx, model)
+	if cleanupErr == nil && len(blockers) == 0 {
+		return ctrl.Result{}, r.removeFinalizer(ctx, model)
 	}
 
-	// Delete all main Datasets
-	if err := r.deleteMainDatasets(ctx, model); err != nil {
-		return ctrl.Result{}, fmt.Errorf("delete main datasets: %w", err)
+	timeout := r.deletionCleanupTimeout()
+	remaining := time.Until(model.DeletionTimestamp.Add(timeout))
+	if remaining <= 0 && model.Annotations[modelv1.ForceReleaseAnnotation] == "true" {
+		log.FromContext(ctx).Info("Force-releasing Model before its cleanup finished", "blockers", blockers, "error", cleanupErr)
+		return ctrl.Result{}, r.removeFinalizer(ctx, model)
 	}
 
-	// Remove finalizer
-	model.Finalizers = removeString(model.Finalizers, ModelFinalizer)
-	if err := r.Update(ctx, model); err != nil {
-		return ctrl.Result{}, err
+	setTerminatingCondition(&model.Status, model, blockers, cleanupErr, timeout, remaining <= 0)
+	if err := r.patchStatus(ctx, original, model); err != nil {
+		return ctrl.Result{}, fmt.Errorf("update status: %w", err)
+	}
+	if cleanupErr != nil {
+		return ctrl.Result{}, cleanupErr
 	}
+	// Come back to check the blockers, and when the timeout passes
+	requeue := DeletionCleanupPollInterval
+	if remaining > 0 {
+		requeue = minRequeue(requeue, remaining)
+	}
+	return ctrl.Result{RequeueAfter: requeue}, nil
+}
 
-	return ctrl.Result{}, nil
+func (r *ModelReconciler) removeFinalizer(ctx context.Context, model *modelv1.Model) error {
+	patch := client.MergeFrom(model.DeepCopy())
+	model.Finalizers = removeString(model.Finalizers, ModelFinalizer)
+	return client.IgnoreNotFound(r.Patch(ctx, model, patch))
 }
 
 func (r *ModelReconciler) ensureFinalizer(ctx context.Context, model *modelv1.Model) error {
 	if !containsString(model.Finalizers, ModelFinalizer) {
+		patch := client.MergeFrom(model.DeepCopy())
 		model.Finalizers = append(model.Finalizers, ModelFinalizer)
-		return r.Update(ctx, model)
+		return r.Patch(ctx, model, patch)
 	}
 	return nil
 }
 
-func (r *ModelReconciler) reconcileVersions(ctx context.Context, model *modelv1.Model, source *modelv1.ModelSource) error {
+func (r *ModelReconciler) reconcileVersions(ctx context.Context, model *modelv1.Model, source *modelSource) error {
+	// A failing version must not block the others
+	var errs []error
 	for _, version := range model.Spec.Versions {
 		state := version.State
 		if state == "" {
@@ -128,72 +212,415 @@ func (r *ModelReconciler) reconcileVersions(ctx context.Context, model *modelv1.
 
 		if state == modelv1.ModelVersionStatePresent {
 			if err := r.ensureVersionDataset(ctx, model, source, version); err != nil {
-				return fmt.Errorf("ensure version %s dataset: %w", version.Name, err)
+				errs = append(errs, fmt.Errorf("ensure version %s dataset: %w", version.Name, err))
 			}
 		} else {
 			if err := r.deleteVersionDataset(ctx, model, version.Name); err != nil {
-				return fmt.Errorf("delete version %s dataset: %w", version.Name, err)
+				errs = append(errs, fmt.Errorf("delete version %s dataset: %w", version.Name, err))
 			}
 		}
 	}
-	return nil
+	return utilerrors.NewAggregate(errs)
 }
 
-func (r *ModelReconciler) ensureVersionDataset(ctx context.Context, model *modelv1.Model, source *modelv1.ModelSource, version modelv1.ModelVersion) error {
+func (r *ModelReconciler) ensureVersionDataset(ctx context.Context, model *modelv1.Model, source *modelSource, version modelv1.ModelVersion) error {
+	sv := ensureSyncedVersion(&model.Status, version.Name)
+	defaults := r.Config.For(model.Namespace)
+
+	// A version without a revision syncs the configured default
+	effective := version
+	if effective.Revision == "" {
+		effective.Revision = defaults.Revision
+	}
+
+	// Check the repo and revision upstream before anything is provisioned for them
+	if r.Preflighter == nil {
+		sv.Preflight = nil
+	} else if ok, err := r.preflightVersion(ctx, source, effective, sv); err != nil {
+		if sv.ActiveDataset == "" {
+			return fmt.Errorf("preflight: %w", err)
+		}
+		// An unavailable check does not hold up a version that is already synced
+		log.FromContext(ctx).Error(err, "Upstream preflight check unavailable", "version", version.Name)
+	} else if !ok {
+		// A failing version gets no new Dataset, while the existing ones are still tended
+		if err := r.abandonPendingDataset(ctx, model, sv); err != nil {
+			return fmt.Errorf("delete pending dataset: %w", err)
+		}
+		if err := r.gcRetiredDatasets(ctx, model, sv, time.Now()); err != nil {
+			return fmt.Errorf("delete retired datasets: %w", err)
+		}
+		return nil
+	}
+
+	// Resolve the revision to the commit the Dataset should sync
+	revision := r.resolveRevision(ctx, source, effective, sv)
+	pinned := effective
+	pinned.Revision = revision
+
 	// Build Dataset spec
-	spec, err := dataset.BuildDatasetSpec(ctx, r.Client, version, *source, model.Namespace)
+	spec, err := dataset.BuildDatasetSpec(ctx, r.Client, pinned, source.Spec, source.SecretNamespace)
 	if err != nil {
 		return fmt.Errorf("build dataset spec: %w", err)
 	}
+	if source.Spec.SecretRef != "" && source.Spec.SecretMode == modelv1.SecretModeReference {
+		secretName, err := r.ensureDatasetSecret(ctx, model, source, defaults.DatasetNamePrefix)
+		if err != nil {
+			return fmt.Errorf("ensure dataset secret: %w", err)
+		}
+		spec.SecretRef = secretName
+	}
+
+	// A changed version is synced into a new Dataset while the active one keeps serving
+	versionHash := calculateVersionHash(version, defaults.Revision, sv.ResolvedRevision)
+	if sv.ActiveDataset == "" {
+		// Pick up a Dataset created for the version before it was recorded, e.g. under a legacy name
+		legacyHash := calculateVersionHash(version, "", sv.ResolvedRevision)
+		if err := r.adoptVersionDataset(ctx, model, version.Name, defaults.DatasetNamePrefix, legacyHash, sv); err != nil {
+			return fmt.Errorf("adopt dataset: %w", err)
+		}
+	}
+	if sv.ActiveDataset != "" && sv.ObservedVersionHash != versionHash && slices.Contains(legacyVersionHashes(version, defaults.Revision, sv.ResolvedRevision), sv.ObservedVersionHash) {
+		// A Ready Dataset synced before the revision resolved, or by a release that hashed
+		// versions differently, keeps serving the same spec; it only records the current hash
+		ready, err := datasetReady(ctx, r.Client, model.Namespace, sv.ActiveDataset)
+		if err != nil {
+			return fmt.Errorf("get dataset: %w", err)
+		}
+		if ready {
+			sv.ObservedVersionHash = versionHash
+		}
+	}
+	datasetName := sv.ActiveDataset
+	rollout := datasetName == "" || (sv.ObservedVersionHash != "" && sv.ObservedVersionHash != versionHash)
+	if rollout {
+		datasetName = dataset.GetVersionDatasetName(defaults.DatasetNamePrefix, model.Name, version.Name, versionHash)
+	}
+
+	// Start a new sync round if a resync was requested since the last one handled
+	request := resyncRequest(model, version.Name)
+	resync := request != "" && request != sv.LastResyncHandled
+	existing := &datasetv1alpha1.Dataset{}
+	key := types.NamespacedName{Name: datasetName, Namespace: model.Namespace}
+	if err := r.Get(ctx, key, existing); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("get dataset: %w", err)
+		}
+		existing = nil
+	}
+
+	// Size the PVC of a new Dataset from the upstream files unless the version sets storage
+	if err := r.sizeVersionStorage(ctx, source, pinned, sv, spec, existing, defaults); err != nil {
+		return fmt.Errorf("size storage: %w", err)
+	}
+	// Bind a new Dataset to the volume the Retain policy kept for the version
+	if err := r.retainedVolumeTemplate(ctx, model, version.Name, spec, existing); err != nil {
+		return fmt.Errorf("find retained volume: %w", err)
+	}
 
-	// Get dataset name
-	datasetName := dataset.GetDatasetName(model.Name, version.Name)
+	if resync {
+		sv.LastResyncRequest = request
+		if existing != nil {
+			spec.DataSyncRound = existing.Spec.DataSyncRound + 1
+		}
+	} else if secretRotated(model, source) && existing != nil && existing.Status.Phase == datasetv1alpha1.DatasetStatusPhaseFailed {
+		// Retry a failed sync with the rotated credentials
+		spec.DataSyncRound = existing.Spec.DataSyncRound + 1
+	}
 
 	// Create owner reference
 	gvk := modelv1.GroupVersion.WithKind("Model")
 	ownerRef := metav1.NewControllerRef(model, gvk)
 
 	// Ensure Dataset
-	if err := dataset.EnsureDataset(ctx, r.Client, datasetName, model.Namespace, spec, ownerRef); err != nil {
+	if err := dataset.EnsureDataset(ctx, r.Client, datasetName, model.Namespace, spec,
+		dataset.Labels(model.Namespace, model.Name, version.Name), dataset.Annotations(model.Namespace, model.Name, version.Name), ownerRef); err != nil {
 		return fmt.Errorf("ensure dataset: %w", err)
 	}
+	if resync {
+		sv.LastResyncHandled = request
+	}
+	if volumeName := spec.VolumeClaimTemplate.Spec.VolumeName; volumeName != "" {
+		if err := r.claimRetainedVolume(ctx, model, sv, volumeName, datasetName); err != nil {
+			return err
+		}
+	}
+
+	if rollout {
+		if err := r.rolloutVersionDataset(ctx, model, sv, datasetName, versionHash, existing); err != nil {
+			return fmt.Errorf("roll out dataset %s: %w", datasetName, err)
+		}
+	} else if err := r.abandonPendingDataset(ctx, model, sv); err != nil {
+		return fmt.Errorf("delete pending dataset: %w", err)
+	}
+
+	// Delete retired Datasets whose grace period ended
+	if err := r.gcRetiredDatasets(ctx, model, sv, time.Now()); err != nil {
+		return fmt.Errorf("delete retired datasets: %w", err)
+	}
 
 	return nil
 }
 
-func (r *ModelReconciler) deleteVersionDataset(ctx context.Context, model *modelv1.Model, versionName string) error {
-	datasetName := dataset.GetDatasetName(model.Name, versionName)
-	ds := &datasetv1alpha1.Dataset{}
-	key := types.NamespacedName{Name: datasetName, Namespace: model.Namespace}
-	if err := r.Get(ctx, key, ds); err != nil {
-		if errors.IsNotFound(err) {
-			return nil
+// resolveRevision returns the revision the Dataset should sync.
+// A branch or tag is resolved to a commit SHA when first seen and recorded in the
+// synced version; it is resolved again when the repo or revision changes, or when
+// the update policy is due for an upstream check. The Pin policy syncs the recorded
+// commit, while Follow passes the revision through and only records the commit.
+// A revision whose first lookup fails is passed through as well, and looked up again
+// every RevisionRetryInterval until it resolves.
+func (r *ModelReconciler) resolveRevision(ctx context.Context, source *modelSource, version modelv1.ModelVersion, sv *modelv1.SyncedVersion) string {
+	if r.Resolver == nil {
+		sv.ResolvedRevision, sv.ResolvedFrom = "", ""
+		return version.Revision
+	}
+
+	from := version.Repo + "@" + version.Revision
+	now := metav1.Now()
+
+	switch {
+	case sv.ResolvedFrom != from || sv.ResolvedRevision == "":
+		// A failed lookup is retried after RevisionRetryInterval rather than on every reconcile
+		if sv.ResolveError != "" && sv.ResolvedFrom == from && sv.LastUpdateCheck != nil && now.Time.Before(sv.LastUpdateCheck.Add(RevisionRetryInterval)) {
+			return version.Revision
+		}
+		sha, err := r.lookupRevision(ctx, source, version.Repo, version.Revision)
+		if err != nil {
+			sv.ResolvedRevision, sv.ResolvedFrom, sv.ResolveError = "", "", ""
+			if goerrors.Is(err, upstream.ErrUnsupported) {
+				return version.Revision
+			}
+			// The version syncs the revision as written until the lookup succeeds
+			log.FromContext(ctx).Error(err, "Revision lookup failed, syncing the revision unpinned", "version", version.Name)
+			sv.ResolvedFrom, sv.ResolveError, sv.LastUpdateCheck = from, err.Error(), &now
+			return version.Revision
+		}
+		sv.ResolvedRevision, sv.ResolvedFrom, sv.ResolveError = sha, from, ""
+		sv.LatestRevision, sv.LastUpdateCheck = sha, &now
+	case updateCheckDue(version, sv, now.Time):
+		sha, err := r.lookupRevision(ctx, source, version.Repo, version.Revision)
+		if err != nil {
+			// A failed check keeps the current revision; it is retried at the next interval
+			log.FromContext(ctx).Error(err, "Upstream update check failed", "version", version.Name)
+			sv.LastUpdateCheck = &now
+			break
+		}
+		sv.LatestRevision, sv.LastUpdateCheck = sha, &now
+		if updateMode(version) == modelv1.UpdateModeAuto {
+			sv.ResolvedRevision = sha
 		}
-		return err
 	}
 
-	// Mark observedState=ABSENT in status first
-	if err := r.markVersionAbsent(ctx, model, versionName); err != nil {
-		return err
+	if version.RevisionPolicy == modelv1.RevisionPolicyFollow {
+		return version.Revision
 	}
+	return sv.ResolvedRevision
+}
 
-	// Delete Dataset
-	return r.Delete(ctx, ds)
+// updateMode returns the update mode of a version, defaulting to Manual.
+func updateMode(version modelv1.ModelVersion) modelv1.UpdateMode {
+	if version.UpdatePolicy == nil || version.UpdatePolicy.Mode == "" {
+		return modelv1.UpdateModeManual
+	}
+	return version.UpdatePolicy.Mode
 }
 
-func (r *ModelReconciler) markVersionAbsent(ctx context.Context, model *modelv1.Model, versionName string) error {
-	status := model.Status.DeepCopy()
-	for i, sv := range status.SyncedVersions {
-		if sv.Name == versionName {
-			status.SyncedVersions[i].ObservedState = modelv1.ModelVersionStateAbsent
-			model.Status = *status
-			return r.Status().Update(ctx, model)
+// updateCheckInterval returns the upstream check interval of a version.
+func updateCheckInterval(version modelv1.ModelVersion) time.Duration {
+	if version.UpdatePolicy == nil || version.UpdatePolicy.Interval == nil || version.UpdatePolicy.Interval.Duration <= 0 {
+		return DefaultUpdateCheckInterval
+	}
+	return version.UpdatePolicy.Interval.Duration
+}
+
+// updateCheckDue reports whether the upstream revision of a version should be checked again.
+func updateCheckDue(version modelv1.ModelVersion, sv *modelv1.SyncedVersion, now time.Time) bool {
+	if updateMode(version) == modelv1.UpdateModeManual {
+		return false
+	}
+	if sv.LastUpdateCheck == nil {
+		return true
+	}
+	return !now.Before(sv.LastUpdateCheck.Add(updateCheckInterval(version)))
+}
+
+// nextUpdateCheck returns how long until the next upstream check of any tracked version is due, or 0 if none is tracked.
+func nextUpdateCheck(model *modelv1.Model, now time.Time) time.Duration {
+	var next time.Duration
+	for _, version := range model.Spec.Versions {
+		if version.State == modelv1.ModelVersionStateAbsent || updateMode(version) == modelv1.UpdateModeManual {
+			continue
+		}
+		sv := findSyncedVersion(&model.Status, version.Name)
+		if sv == nil || sv.LastUpdateCheck == nil {
+			continue
+		}
+		wait := sv.LastUpdateCheck.Add(updateCheckInterval(version)).Sub(now)
+		if wait < time.Second {
+			wait = time.Second
 		}
+		if next == 0 || wait < next {
+			next = wait
+		}
+	}
+	return next
+}
+
+// nextRevisionRetry returns how long until the next unresolved revision is due for another lookup, or 0 if there is none.
+func nextRevisionRetry(model *modelv1.Model, now time.Time) time.Duration {
+	var next time.Duration
+	for i := range model.Status.SyncedVersions {
+		sv := &model.Status.SyncedVersions[i]
+		if sv.ResolveError == "" || sv.LastUpdateCheck == nil {
+			continue
+		}
+		wait := sv.LastUpdateCheck.Add(RevisionRetryInterval).Sub(now)
+		if wait < time.Second {
+			wait = time.Second
+		}
+		if next == 0 || wait < next {
+			next = wait
+		}
+	}
+	return next
+}
+
+// lookupRevision resolves a revision against the source using the ModelSource credentials.
+func (r *ModelReconciler) lookupRevision(ctx context.Context, source *modelSource, repo, revision string) (string, error) {
+	creds, err := r.sourceCredentials(ctx, source)
+	if err != nil {
+		return "", err
+	}
+	return r.Resolver.ResolveRevision(ctx, source.Spec, creds, repo, revision)
+}
+
+// sourceCredentials reads the credentials of a source from its Secret, if any.
+func (r *ModelReconciler) sourceCredentials(ctx context.Context, source *modelSource) (upstream.Credentials, error) {
+	if source.Spec.SecretRef == "" {
+		return upstream.Credentials{}, nil
+	}
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: source.SecretNamespace, Name: source.Spec.SecretRef}
+	if err := r.Get(ctx, key, secret); err != nil {
+		return upstream.Credentials{}, fmt.Errorf("get secret %s: %w", source.Spec.SecretRef, err)
+	}
+	mapped, err := dataset.MapSecretData(source.Spec, secret.Data)
+	if err != nil {
+		return upstream.Credentials{}, err
+	}
+	return upstream.CredentialsFromSecretData(mapped), nil
+}
+
+// ensureDatasetSecret returns the Secret a Dataset should reference in Reference mode.
+// The source Secret is referenced directly when it lives in the Model namespace under
+// the loader's key names; otherwise the mapped keys are copied into a Secret owned by the Model.
+func (r *ModelReconciler) ensureDatasetSecret(ctx context.Context, model *modelv1.Model, source *modelSource, prefix string) (string, error) {
+	if source.SecretNamespace == model.Namespace && len(source.Spec.SecretKeys) == 0 {
+		return source.Spec.SecretRef, nil
+	}
+
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: source.SecretNamespace, Name: source.Spec.SecretRef}
+	if err := r.Get(ctx, key, secret); err != nil {
+		return "", fmt.Errorf("get secret %s: %w", source.Spec.SecretRef, err)
+	}
+	mapped, err := dataset.MapSecretData(source.Spec, secret.Data)
+	if err != nil {
+		return "", err
+	}
+
+	name := dataset.GetDatasetSecretName(prefix, model.Name)
+	ownerRef := metav1.NewControllerRef(model, modelv1.GroupVersion.WithKind("Model"))
+	if err := dataset.EnsureDatasetSecret(ctx, r.Client, name, model.Namespace, mapped, ownerRef); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// secretRotated reports whether the source credentials changed since the Datasets were last synced.
+func secretRotated(model *modelv1.Model, source *modelSource) bool {
+	observed := model.Status.ObservedSecretHash
+	return observed != "" && source.Status.SecretHash != "" && observed != source.Status.SecretHash
+}
+
+// resyncRequest returns the latest resync request that applies to a version,
+// taking the later of the model-wide and the per-version annotation.
+func resyncRequest(model *modelv1.Model, versionName string) string {
+	modelRequest := model.Annotations[modelv1.ResyncAnnotation]
+	versionRequest := model.Annotations[modelv1.VersionResyncAnnotationPrefix+versionName]
+	if versionRequest == "" {
+		return modelRequest
+	}
+	if modelRequest == "" {
+		return versionRequest
+	}
+
+	modelTime, modelErr := time.Parse(time.RFC3339Nano, modelRequest)
+	versionTime, versionErr := time.Parse(time.RFC3339Nano, versionRequest)
+	if modelErr == nil && versionErr == nil && modelTime.After(versionTime) {
+		return modelRequest
+	}
+	return versionRequest
+}
+
+func (r *ModelReconciler) deleteVersionDataset(ctx context.Context, model *modelv1.Model, versionName string) error {
+	sv := findSyncedVersion(&model.Status, versionName)
+	if sv == nil {
+		return nil
+	}
+
+	// Collect the active, pending and retiring Datasets of the version
+	var datasets []*datasetv1alpha1.Dataset
+	for _, datasetName := range versionDatasetNames(sv) {
+		ds := &datasetv1alpha1.Dataset{}
+		key := types.NamespacedName{Name: datasetName, Namespace: model.Namespace}
+		if err := r.Get(ctx, key, ds); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		datasets = append(datasets, ds)
+	}
+	if len(datasets) == 0 {
+		return nil
 	}
+
+	// Mark observedState=ABSENT in status first
+	sv.ObservedState = modelv1.ModelVersionStateAbsent
+
+	// Delete Datasets; the deletion policy decides what is kept of the active one
+	policy := deletionPolicy(model, versionName)
+	for _, ds := range datasets {
+		if ds.Name == sv.ActiveDataset && ds.DeletionTimestamp.IsZero() {
+			switch policy {
+			case modelv1.DeletionPolicyOrphan:
+				if err := r.orphanDataset(ctx, model, ds); err != nil {
+					return err
+				}
+				sv.OrphanedDataset = ds.Name
+				sv.ActiveDataset = ""
+				continue
+			case modelv1.DeletionPolicyRetain:
+				volume, err := r.retainVolume(ctx, model, ds)
+				if err != nil {
+					return err
+				}
+				if volume != "" {
+					sv.RetainedVolume = volume
+				}
+			}
+		}
+		if err := r.Delete(ctx, ds); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	sv.PendingDataset = ""
+	sv.RetiringDatasets = nil
 	return nil
 }
 
-func (r *ModelReconciler) syncStatus(ctx context.Context, model *modelv1.Model) error {
+func (r *ModelReconciler) syncStatus(ctx context.Context, model *modelv1.Model, shareErr error) error {
 	status := model.Status.DeepCopy()
 	status.ObservedGeneration = model.Generation
 
@@ -206,10 +633,19 @@ func (r *ModelReconciler) syncStatus(ctx context.Context, model *modelv1.Model)
 	// Sync each version
 	syncedVersions := make([]modelv1.SyncedVersion, 0)
 	for _, version := range model.Spec.Versions {
-		sv, err := r.syncVersionStatus(ctx, model, version.Name)
+		previous := findSyncedVersion(status, version.Name)
+		activeDataset := ""
+		if previous != nil {
+			activeDataset = previous.ActiveDataset
+		}
+		sv, err := r.syncVersionStatus(ctx, model, version.Name, activeDataset)
 		if err != nil {
 			return fmt.Errorf("sync version %s status: %w", version.Name, err)
 		}
+		// Carry over fields recorded by the controller rather than read from the Dataset
+		if previous != nil {
+			carryOverSyncedVersion(sv, previous)
+		}
 		syncedVersions = append(syncedVersions, *sv)
 	}
 
@@ -217,9 +653,8 @@ func (r *ModelReconciler) syncStatus(ctx context.Context, model *modelv1.Model)
 	for _, sv := range status.SyncedVersions {
 		if !specVersions[sv.Name] && sv.ObservedState != modelv1.ModelVersionStateAbsent {
 			// Check if Dataset still exists
-			datasetName := dataset.GetDatasetName(model.Name, sv.Name)
 			ds := &datasetv1alpha1.Dataset{}
-			key := types.NamespacedName{Name: datasetName, Namespace: model.Namespace}
+			key := types.NamespacedName{Name: sv.ActiveDataset, Namespace: model.Namespace}
 			if err := r.Get(ctx, key, ds); err == nil {
 				// Dataset still exists, keep status entry
 				sv.ObservedState = modelv1.ModelVersionStateAbsent
@@ -229,18 +664,211 @@ func (r *ModelReconciler) syncStatus(ctx context.Context, model *modelv1.Model)
 	}
 
 	status.SyncedVersions = syncedVersions
+	setModelConditions(status, model, shareErr)
+	meta.RemoveStatusCondition(&status.Conditions, modelv1.ModelConditionReconcileError)
 	model.Status = *status
-	return r.Status().Update(ctx, model)
+	return nil
+}
+
+// setModelConditions derives the Ready, Progressing and Degraded conditions
+// from the synced versions of all PRESENT versions.
+func setModelConditions(status *modelv1.ModelStatus, model *modelv1.Model, shareErr error) {
+	var present, notReady, syncing, failed, blocked []string
+	for _, version := range model.Spec.Versions {
+		if version.State == modelv1.ModelVersionStateAbsent {
+			continue
+		}
+		present = append(present, version.Name)
+
+		phase := ""
+		if sv := findSyncedVersion(status, version.Name); sv != nil {
+			phase = sv.Phase
+			// A failed upstream check blocks the version until the repo or access is fixed
+			if preflightFailed(sv) {
+				blocked = append(blocked, fmt.Sprintf("%s (%s)", version.Name, sv.Preflight.Reason))
+				if sv.ActiveDataset == "" {
+					notReady = append(notReady, version.Name)
+					continue
+				}
+			}
+			// A pending Dataset is syncing while the active one keeps serving
+			if sv.PendingDataset != "" && phase == string(datasetv1alpha1.DatasetStatusPhaseReady) {
+				syncing = append(syncing, version.Name)
+			}
+		}
+		switch datasetv1alpha1.DatasetStatusPhase(phase) {
+		case datasetv1alpha1.DatasetStatusPhaseReady:
+		case datasetv1alpha1.DatasetStatusPhaseFailed:
+			failed = append(failed, version.Name)
+			notReady = append(notReady, version.Name)
+		default:
+			// Pending, Processing, or no Dataset phase reported yet
+			syncing = append(syncing, version.Name)
+			notReady = append(notReady, version.Name)
+		}
+	}
+
+	ready := metav1.Condition{
+		Type:    modelv1.ModelConditionReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  modelv1.ReasonAllVersionsReady,
+		Message: fmt.Sprintf("%d version(s) ready", len(present)),
+	}
+	if len(present) == 0 {
+		ready.Reason = modelv1.ReasonNoVersionsPresent
+		ready.Message = "No versions are in state PRESENT"
+	} else if len(notReady) > 0 {
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = modelv1.ReasonVersionsNotReady
+		ready.Message = fmt.Sprintf("Versions not ready: %s", strings.Join(notReady, ", "))
+	}
+
+	progressing := metav1.Condition{
+		Type:    modelv1.ModelConditionProgressing,
+		Status:  metav1.ConditionFalse,
+		Reason:  modelv1.ReasonSyncComplete,
+		Message: "No versions are syncing",
+	}
+	if len(syncing) > 0 {
+		progressing.Status = metav1.ConditionTrue
+		progressing.Reason = modelv1.ReasonDatasetsSyncing
+		progressing.Message = fmt.Sprintf("Versions syncing: %s", strings.Join(syncing, ", "))
+	}
+
+	degraded := metav1.Condition{
+		Type:    modelv1.ModelConditionDegraded,
+		Status:  metav1.ConditionFalse,
+		Reason:  modelv1.ReasonAsExpected,
+		Message: "All Datasets and shares are healthy",
+	}
+	if len(failed) > 0 {
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = modelv1.ReasonDatasetFailed
+		degraded.Message = fmt.Sprintf("Datasets failed for versions: %s", strings.Join(failed, ", "))
+	} else if len(blocked) > 0 {
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = modelv1.ReasonPreflightFailed
+		degraded.Message = fmt.Sprintf("Upstream checks failed for versions: %s", strings.Join(blocked, ", "))
+	} else if shareErr != nil {
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = modelv1.ReasonShareFailed
+		degraded.Message = shareErr.Error()
+	}
+
+	for _, condition := range []metav1.Condition{ready, progressing, degraded} {
+		condition.ObservedGeneration = model.Generation
+		meta.SetStatusCondition(&status.Conditions, condition)
+	}
+
+	setUpdateAvailableCondition(status, model)
+	setRevisionUnresolvedCondition(status, model)
+	setStorageDefaultedCondition(status, model)
+	setSharedCondition(status, model)
 }
 
-func (r *ModelReconciler) syncVersionStatus(ctx context.Context, model *modelv1.Model, versionName string) (*modelv1.SyncedVersion, error) {
-	datasetName := dataset.GetDatasetName(model.Name, versionName)
+// setUpdateAvailableCondition reports versions whose upstream revision moved past the synced commit.
+// The condition is only present while at least one version tracks its upstream revision.
+func setUpdateAvailableCondition(status *modelv1.ModelStatus, model *modelv1.Model) {
+	tracked := false
+	var updates []string
+	for _, version := range model.Spec.Versions {
+		if version.State == modelv1.ModelVersionStateAbsent || updateMode(version) == modelv1.UpdateModeManual {
+			continue
+		}
+		tracked = true
+		sv := findSyncedVersion(status, version.Name)
+		if sv != nil && sv.LatestRevision != "" && sv.LatestRevision != sv.ResolvedRevision {
+			updates = append(updates, fmt.Sprintf("%s (%s -> %s)", version.Name, shortSHA(sv.ResolvedRevision), shortSHA(sv.LatestRevision)))
+		}
+	}
+
+	if !tracked {
+		meta.RemoveStatusCondition(&status.Conditions, modelv1.ModelConditionUpdateAvailable)
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               modelv1.ModelConditionUpdateAvailable,
+		Status:             metav1.ConditionFalse,
+		Reason:             modelv1.ReasonUpToDate,
+		Message:            "All tracked versions are at the latest upstream commit",
+		ObservedGeneration: model.Generation,
+	}
+	if len(updates) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = modelv1.ReasonUpstreamUpdated
+		condition.Message = fmt.Sprintf("Upstream updates available: %s", strings.Join(updates, ", "))
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+// setRevisionUnresolvedCondition reports versions that sync their revision unpinned because
+// it could not be resolved to a commit.
+func setRevisionUnresolvedCondition(status *modelv1.ModelStatus, model *modelv1.Model) {
+	var unresolved []string
+	for _, version := range model.Spec.Versions {
+		if version.State == modelv1.ModelVersionStateAbsent {
+			continue
+		}
+		if sv := findSyncedVersion(status, version.Name); sv != nil && sv.ResolveError != "" {
+			unresolved = append(unresolved, fmt.Sprintf("%s (%s)", version.Name, sv.ResolveError))
+		}
+	}
+
+	if len(unresolved) == 0 {
+		meta.RemoveStatusCondition(&status.Conditions, modelv1.ModelConditionRevisionUnresolved)
+		return
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               modelv1.ModelConditionRevisionUnresolved,
+		Status:             metav1.ConditionTrue,
+		Reason:             modelv1.ReasonLookupFailed,
+		Message:            fmt.Sprintf("Syncing revisions unpinned until they resolve: %s", strings.Join(unresolved, ", ")),
+		ObservedGeneration: model.Generation,
+	})
+}
+
+// setStorageDefaultedCondition reports versions whose PVC got the default storage size because
+// their upstream files could not be listed.
+func setStorageDefaultedCondition(status *modelv1.ModelStatus, model *modelv1.Model) {
+	var defaulted []string
+	for _, version := range model.Spec.Versions {
+		if version.State == modelv1.ModelVersionStateAbsent {
+			continue
+		}
+		if sv := findSyncedVersion(status, version.Name); sv != nil && sv.EstimateError != "" {
+			defaulted = append(defaulted, fmt.Sprintf("%s (%s)", version.Name, sv.EstimateError))
+		}
+	}
+
+	if len(defaulted) == 0 {
+		meta.RemoveStatusCondition(&status.Conditions, modelv1.ModelConditionStorageDefaulted)
+		return
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               modelv1.ModelConditionStorageDefaulted,
+		Status:             metav1.ConditionTrue,
+		Reason:             modelv1.ReasonListFilesFailed,
+		Message:            fmt.Sprintf("Sized with the default storage size: %s", strings.Join(defaulted, ", ")),
+		ObservedGeneration: model.Generation,
+	})
+}
+
+func (r *ModelReconciler) syncVersionStatus(ctx context.Context, model *modelv1.Model, versionName, datasetName string) (*modelv1.SyncedVersion, error) {
+	if datasetName == "" {
+		return &modelv1.SyncedVersion{
+			Name:          versionName,
+			ObservedState: modelv1.ModelVersionStateAbsent,
+		}, nil
+	}
+
 	ds := &datasetv1alpha1.Dataset{}
 	key := types.NamespacedName{Name: datasetName, Namespace: model.Namespace}
 	if err := r.Get(ctx, key, ds); err != nil {
 		if errors.IsNotFound(err) {
 			return &modelv1.SyncedVersion{
 				Name:          versionName,
+				ActiveDataset: datasetName,
 				ObservedState: modelv1.ModelVersionStateAbsent,
 			}, nil
 		}
@@ -277,45 +905,84 @@ func (r *ModelReconciler) syncVersionStatus(ctx context.Context, model *modelv1.
 		}
 	}
 
-	// Calculate version hash
-	versionHash := r.calculateVersionHash(model, versionName)
-	sv.ObservedVersionHash = versionHash
-
 	return sv, nil
 }
 
-func (r *ModelReconciler) calculateVersionHash(model *modelv1.Model, versionName string) string {
-	for _, v := range model.Spec.Versions {
-		if v.Name == versionName {
-			// Hash: repo + revision + storage spec
-			data := fmt.Sprintf("%s:%s:%v", v.Repo, v.Revision, v.Storage)
-			hash := sha256.Sum256([]byte(data))
-			return hex.EncodeToString(hash[:])[:8]
-		}
+// calculateVersionHash hashes the fields of a version that determine the Dataset content. The
+// revision is hashed as the effective one, so an unset revision and the default it stands for
+// hash the same and defaulting the spec does not roll the version out.
+func calculateVersionHash(version modelv1.ModelVersion, defaultRevision, resolvedRevision string) string {
+	revision := version.Revision
+	if revision == "" {
+		revision = defaultRevision
+	}
+	// Hash: repo + revision + storage spec, plus the resolved commit when there is one
+	data := fmt.Sprintf("%s:%s:%v", version.Repo, revision, version.Storage)
+	if resolvedRevision != "" {
+		data = fmt.Sprintf("%s:%s", data, resolvedRevision)
+	}
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])[:8]
+}
+
+// legacyVersionHashes returns the other hashes a Dataset of the same version spec may have been
+// recorded under: without the resolved commit, as before the revision resolved or before
+// revisions were resolved at all, and with an unset revision hashed as written rather than as
+// the default.
+func legacyVersionHashes(version modelv1.ModelVersion, defaultRevision, resolvedRevision string) []string {
+	return []string{
+		calculateVersionHash(version, defaultRevision, ""),
+		calculateVersionHash(version, "", ""),
+		calculateVersionHash(version, "", resolvedRevision),
 	}
-	return ""
 }
 
 func (r *ModelReconciler) reconcileSharing(ctx context.Context, model *modelv1.Model) error {
-	// Find all versions with sharing enabled
+	// A failing version must not keep the others from being shared
+	var errs []error
+	specVersions := make(map[string]bool, len(model.Spec.Versions))
 	for _, version := range model.Spec.Versions {
-		if version.Share != nil && version.Share.Enabled {
+		specVersions[version.Name] = true
+		// An ABSENT version has no Dataset left to reference
+		if version.Share != nil && version.Share.Enabled && version.State != modelv1.ModelVersionStateAbsent {
 			if err := r.reconcileVersionSharing(ctx, model, version); err != nil {
-				return fmt.Errorf("reconcile version %s sharing: %w", version.Name, err)
+				errs = append(errs, fmt.Errorf("reconcile version %s sharing: %w", version.Name, err))
 			}
 		} else {
 			// Clean up sharing if disabled
 			if err := r.cleanupVersionSharing(ctx, model, version.Name); err != nil {
-				return fmt.Errorf("cleanup version %s sharing: %w", version.Name, err)
+				errs = append(errs, fmt.Errorf("cleanup version %s sharing: %w", version.Name, err))
 			}
 		}
 	}
-	return nil
+	// Withdraw the shares of versions removed from spec
+	for _, sv := range model.Status.SyncedVersions {
+		if specVersions[sv.Name] {
+			continue
+		}
+		if err := r.cleanupVersionSharing(ctx, model, sv.Name); err != nil {
+			errs = append(errs, fmt.Errorf("cleanup version %s sharing: %w", sv.Name, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
 }
 
+// reconcileVersionSharing creates a REFERENCE Dataset to the active Dataset of a version in
+// every namespace the share matches and grants, and deletes those in namespaces that no longer
+// match, lost their approval or expired. New references wait until the active Dataset is Ready,
+// and a reference to a replaced Dataset is deleted before one to the new Dataset is created.
+// The outcome per namespace is recorded in the share targets of the synced version.
 func (r *ModelReconciler) reconcileVersionSharing(ctx context.Context, model *modelv1.Model, version modelv1.ModelVersion) error {
-	// Get source dataset name
-	sourceDatasetName := dataset.GetDatasetName(model.Name, version.Name)
+	// Share the active Dataset; there is nothing to share until one exists
+	sv := findSyncedVersion(&model.Status, version.Name)
+	if sv == nil || sv.ActiveDataset == "" {
+		return nil
+	}
+	sourceDatasetName := sv.ActiveDataset
+	sourceReady, err := datasetReady(ctx, r.Client, model.Namespace, sourceDatasetName)
+	if err != nil {
+		return fmt.Errorf("get source dataset: %w", err)
+	}
 
 	// Find matching namespaces
 	namespaces, err := r.findMatchingNamespaces(ctx, version.Share)
@@ -323,23 +990,100 @@ func (r *ModelReconciler) reconcileVersionSharing(ctx context.Context, model *mo
 		return fmt.Errorf("find matching namespaces: %w", err)
 	}
 
-	// Create REFERENCE Datasets
-	labels := buildModelLabels(model.Namespace, model.Name, version.Name)
+	// In Copy mode the targets are restored from a snapshot of the active Dataset's PVC
+	copyMode := version.Share.Mode == modelv1.ShareModeCopy
+	var snap *shareCopySource
+	var errs []error
+	if copyMode {
+		if snap, err = r.ensureShareSnapshot(ctx, model, version, sv, sourceReady); err != nil {
+			errs = append(errs, fmt.Errorf("snapshot source: %w", err))
+		}
+	}
+
+	targets := make(map[string]bool, len(namespaces))
+	shareTargets := make([]modelv1.ShareTarget, 0, len(namespaces))
+	now := metav1.Now()
 
 	for _, ns := range namespaces {
 		if ns == model.Namespace {
 			continue // Skip source namespace
 		}
 
-		targetName := dataset.GetReferenceDatasetName(model.Namespace, model.Name, version.Name)
-		if err := dataset.EnsureReferenceDataset(ctx, r.Client, model.Namespace, sourceDatasetName, ns, targetName, labels); err != nil {
-			return fmt.Errorf("ensure reference dataset in %s: %w", ns, err)
+		target := grantShare(model, version, sv, ns, now)
+		if target.State != modelv1.ShareTargetShared {
+			shareTargets = append(shareTargets, target)
+			continue
 		}
+		targets[ns] = true
+
+		if copyMode {
+			err = r.shareCopy(ctx, model, version.Name, ns, snap, &target)
+		} else {
+			err = r.shareReference(ctx, model, version.Name, ns, sourceDatasetName, sourceReady, &target)
+		}
+		if err != nil {
+			target.LastError = err.Error()
+			errs = append(errs, err)
+		}
+		shareTargets = append(shareTargets, target)
+	}
+	sort.Slice(shareTargets, func(i, j int) bool { return shareTargets[i].Namespace < shareTargets[j].Namespace })
+	sv.ShareTargets = shareTargets
+
+	// Delete the references and copies in namespaces that stopped matching or are no longer
+	// granted, and all of those of the mode not in use
+	referenceTargets, copyTargets := targets, map[string]bool(nil)
+	if copyMode {
+		referenceTargets, copyTargets = nil, targets
+	}
+	if err := r.deleteReferenceDatasetsExcept(ctx, model, version.Name, referenceTargets); err != nil {
+		errs = append(errs, fmt.Errorf("delete stale reference datasets: %w", err))
+	}
+	if err := r.deleteShareCopiesExcept(ctx, model, version.Name, copyTargets); err != nil {
+		errs = append(errs, fmt.Errorf("delete stale copies: %w", err))
+	}
+	if !copyMode {
+		if err := r.deleteShareSnapshot(ctx, model, sv); err != nil {
+			errs = append(errs, fmt.Errorf("delete snapshot: %w", err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// shareReference ensures the REFERENCE Dataset of a granted target namespace and records it on target.
+func (r *ModelReconciler) shareReference(ctx context.Context, model *modelv1.Model, versionName, ns, sourceDatasetName string, sourceReady bool, target *modelv1.ShareTarget) error {
+	targetName, err := r.shareDatasetName(ctx, model, versionName, ns)
+	if err != nil {
+		return fmt.Errorf("find reference dataset in %s: %w", ns, err)
 	}
+	target.Dataset = targetName
 
+	pending, err := referenceWaits(ctx, r.Client, ns, targetName, dataset.ReferenceURI(model.Namespace, sourceDatasetName), sourceReady,
+		func(ds *datasetv1alpha1.Dataset) bool {
+			return !isClaimDataset(ds) && strings.HasPrefix(ds.Spec.Source.URI, dataset.ReferenceURI(model.Namespace, ""))
+		})
+	if err != nil {
+		return fmt.Errorf("check reference dataset in %s: %w", ns, err)
+	}
+	if pending {
+		target.State = modelv1.ShareTargetSharePending
+	} else if err := dataset.EnsureReferenceDataset(ctx, r.Client, model.Namespace, sourceDatasetName, ns, targetName,
+		dataset.Labels(model.Namespace, model.Name, versionName), dataset.Annotations(model.Namespace, model.Name, versionName), nil); err != nil {
+		return fmt.Errorf("ensure reference dataset in %s: %w", ns, err)
+	}
+	target.Phase = r.referenceDatasetPhase(ctx, ns, targetName)
 	return nil
 }
 
+// referenceDatasetPhase returns the phase of a REFERENCE Dataset as seen in the cache, or "" if it is not there yet.
+func (r *ModelReconciler) referenceDatasetPhase(ctx context.Context, namespace, name string) string {
+	ds := &datasetv1alpha1.Dataset{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, ds); err != nil {
+		return ""
+	}
+	return string(ds.Status.Phase)
+}
+
 func (r *ModelReconciler) findMatchingNamespaces(ctx context.Context, share *modelv1.ShareSpec) ([]string, error) {
 	nsList := &corev1.NamespaceList{}
 	if err := r.List(ctx, nsList); err != nil {
@@ -348,62 +1092,92 @@ func (r *ModelReconciler) findMatchingNamespaces(ctx context.Context, share *mod
 
 	var matching []string
 	for _, ns := range nsList.Items {
-		// Check namespace selector
-		if share.NamespaceSelector != nil {
-			selector, err := metav1.LabelSelectorAsSelector(share.NamespaceSelector)
-			if err != nil {
-				return nil, err
-			}
-			if !selector.Matches(labels.Set(ns.Labels)) {
-				continue
-			}
+		ok, err := shareMatchesNamespace(share, &ns)
+		if err != nil {
+			return nil, err
 		}
-
-		// Check opt-in label
-		if share.RequireOptInLabel != "" {
-			key := share.RequireOptInLabel
-			value := "true"
-			if idx := len(key) - 1; idx >= 0 && key[idx] == '=' {
-				// Format: "key=value"
-				parts := splitOptInLabel(share.RequireOptInLabel)
-				if len(parts) == 2 {
-					key, value = parts[0], parts[1]
-				}
-			}
-			if ns.Labels[key] != value {
-				continue
-			}
+		if ok {
+			matching = append(matching, ns.Name)
 		}
-
-		matching = append(matching, ns.Name)
 	}
 
 	return matching, nil
 }
 
-func splitOptInLabel(s string) []string {
-	for i := len(s) - 1; i >= 0; i-- {
-		if s[i] == '=' {
-			return []string{s[:i], s[i+1:]}
+// shareMatchesNamespace reports whether a namespace is selected by the namespace selector of a share
+// and carries its opt-in label. A namespace being deleted no longer receives shares.
+func shareMatchesNamespace(share *modelv1.ShareSpec, ns *corev1.Namespace) (bool, error) {
+	if ok, err := shareSelectsNamespace(share, ns); !ok || err != nil {
+		return false, err
+	}
+
+	// Check opt-in label
+	if share.RequireOptInLabel != "" {
+		key, value := splitOptInLabel(share.RequireOptInLabel)
+		if ns.Labels[key] != value {
+			return false, nil
 		}
 	}
-	return []string{s}
+	return true, nil
+}
+
+// shareSelectsNamespace reports whether a share's namespace selector matches a namespace that is
+// not terminating. Unlike shareMatchesNamespace it ignores the opt-in label, which a ModelClaim replaces.
+func shareSelectsNamespace(share *modelv1.ShareSpec, ns *corev1.Namespace) (bool, error) {
+	if !ns.DeletionTimestamp.IsZero() {
+		return false, nil
+	}
+	if share.NamespaceSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(share.NamespaceSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
+
+// splitOptInLabel splits "key=value" into key and value; a bare key requires the value "true".
+func splitOptInLabel(s string) (string, string) {
+	if key, value, ok := strings.Cut(s, "="); ok {
+		return key, value
+	}
+	return s, "true"
 }
 
 func (r *ModelReconciler) cleanupVersionSharing(ctx context.Context, model *modelv1.Model, versionName string) error {
-	// Find all REFERENCE Datasets with matching labels
+	if err := r.deleteReferenceDatasetsExcept(ctx, model, versionName, nil); err != nil {
+		return err
+	}
+	if err := r.deleteShareCopiesExcept(ctx, model, versionName, nil); err != nil {
+		return err
+	}
+	if sv := findSyncedVersion(&model.Status, versionName); sv != nil {
+		if err := r.deleteShareSnapshot(ctx, model, sv); err != nil {
+			return err
+		}
+		sv.ShareTargets = nil
+	}
+	return nil
+}
+
+// deleteReferenceDatasetsExcept deletes the REFERENCE Datasets of a version outside the keep namespaces.
+func (r *ModelReconciler) deleteReferenceDatasetsExcept(ctx context.Context, model *modelv1.Model, versionName string, keep map[string]bool) error {
+	// Find all REFERENCE Datasets of the model
 	datasetList := &datasetv1alpha1.DatasetList{}
-	labels := buildModelLabels(model.Namespace, model.Name, versionName)
-	labelSelector := client.MatchingLabels(labels)
-	if err := r.List(ctx, datasetList, labelSelector); err != nil {
+	if err := r.List(ctx, datasetList, client.MatchingFields{DatasetModelIndex: formatNamespacedName(model.Namespace, model.Name)}); err != nil {
 		return err
 	}
 
 	for _, ds := range datasetList.Items {
-		if ds.Spec.Source.Type == datasetv1alpha1.DatasetTypeReference {
-			if err := r.Delete(ctx, &ds); err != nil && !errors.IsNotFound(err) {
-				return err
-			}
+		if keep[ds.Namespace] || ds.Spec.Source.Type != datasetv1alpha1.DatasetTypeReference || isClaimDataset(&ds) {
+			continue
+		}
+		if !dataset.IsManagedBy(&ds, model.Namespace, model.Name, versionName) {
+			continue
+		}
+		if err := r.Delete(ctx, &ds); err != nil && !errors.IsNotFound(err) {
+			return err
 		}
 	}
 
@@ -413,17 +1187,13 @@ func (r *ModelReconciler) cleanupVersionSharing(ctx context.Context, model *mode
 func (r *ModelReconciler) deleteReferenceDatasets(ctx context.Context, model *modelv1.Model) error {
 	// Find all REFERENCE Datasets with matching labels
 	datasetList := &datasetv1alpha1.DatasetList{}
-	// Note: version is empty for model-level cleanup
-	labels := buildModelLabels(model.Namespace, model.Name, "")
-	labelSelector := client.MatchingLabels{
-		"modelfs.samzong.dev/model": labels["modelfs.samzong.dev/model"],
-	}
-	if err := r.List(ctx, datasetList, labelSelector); err != nil {
+	if err := r.List(ctx, datasetList, client.MatchingFields{DatasetModelIndex: formatNamespacedName(model.Namespace, model.Name)}); err != nil {
 		return err
 	}
 
 	for _, ds := range datasetList.Items {
-		if ds.Spec.Source.Type == datasetv1alpha1.DatasetTypeReference {
+		// Datasets of ModelClaims are withdrawn by the claim controller
+		if ds.Spec.Source.Type == datasetv1alpha1.DatasetTypeReference && dataset.IsManagedBy(&ds, model.Namespace, model.Name, "") && !isClaimDataset(&ds) {
 			if err := r.Delete(ctx, &ds); err != nil && !errors.IsNotFound(err) {
 				return err
 			}
@@ -436,24 +1206,46 @@ func (r *ModelReconciler) deleteReferenceDatasets(ctx context.Context, model *mo
 func (r *ModelReconciler) deleteMainDatasets(ctx context.Context, model *modelv1.Model) error {
 	// Delete all Datasets owned by this Model
 	datasetList := &datasetv1alpha1.DatasetList{}
-	if err := r.List(ctx, datasetList, client.InNamespace(model.Namespace)); err != nil {
+	if err := r.List(ctx, datasetList, client.InNamespace(model.Namespace),
+		client.MatchingFields{DatasetModelIndex: formatNamespacedName(model.Namespace, model.Name)}); err != nil {
 		return err
 	}
 
 	for _, ds := range datasetList.Items {
-		for _, ownerRef := range ds.OwnerReferences {
-			if ownerRef.Kind == "Model" && ownerRef.Name == model.Name {
-				if err := r.Delete(ctx, &ds); err != nil && !errors.IsNotFound(err) {
+		if !metav1.IsControlledBy(&ds, model) {
+			continue
+		}
+		// The deletion policy of its version decides what is kept of an active Dataset
+		versionName := ds.Annotations[dataset.AnnotationVersion]
+		if sv := findSyncedVersion(&model.Status, versionName); sv != nil && sv.ActiveDataset == ds.Name && ds.DeletionTimestamp.IsZero() {
+			switch deletionPolicy(model, versionName) {
+			case modelv1.DeletionPolicyOrphan:
+				if err := r.orphanDataset(ctx, model, &ds); err != nil {
+					return err
+				}
+				continue
+			case modelv1.DeletionPolicyRetain:
+				if _, err := r.retainVolume(ctx, model, &ds); err != nil {
 					return err
 				}
 			}
 		}
+		if err := r.Delete(ctx, &ds); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func (r *ModelReconciler) isCredentialsReady(source *modelv1.ModelSource) bool {
+func (r *ModelReconciler) operatorNamespace() string {
+	if r.OperatorNamespace == "" {
+		return DefaultOperatorNamespace
+	}
+	return r.OperatorNamespace
+}
+
+func (r *ModelReconciler) isCredentialsReady(source *modelSource) bool {
 	for _, cond := range source.Status.Conditions {
 		if cond.Type == "CredentialsReady" && cond.Status == metav1.ConditionTrue {
 			return true
@@ -462,37 +1254,28 @@ func (r *ModelReconciler) isCredentialsReady(source *modelv1.ModelSource) bool {
 	return false
 }
 
-func (r *ModelReconciler) updateStatusWithError(ctx context.Context, model *modelv1.Model, reason string, err error) (ctrl.Result, error) {
-	status := model.Status.DeepCopy()
-	now := metav1.Now()
-	condition := metav1.Condition{
-		Type:               "ReconcileError",
+// setErrorStatus records a reconcile error in the ReconcileError and Degraded conditions
+// and returns the result that retries it after the error requeue interval.
+func (r *ModelReconciler) setErrorStatus(ctx context.Context, model *modelv1.Model, reason string, err error) (ctrl.Result, error) {
+	meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+		Type:               modelv1.ModelConditionReconcileError,
 		Status:             metav1.ConditionFalse,
 		Reason:             reason,
 		Message:            err.Error(),
-		LastTransitionTime: now,
 		ObservedGeneration: model.Generation,
-	}
-
-	// Update condition
-	found := false
-	for i, c := range status.Conditions {
-		if c.Type == "ReconcileError" {
-			status.Conditions[i] = condition
-			found = true
-			break
-		}
-	}
-	if !found {
-		status.Conditions = append(status.Conditions, condition)
-	}
-
-	model.Status = *status
-	if updateErr := r.Status().Update(ctx, model); updateErr != nil {
-		return ctrl.Result{}, fmt.Errorf("update status: %w (original error: %v)", updateErr, err)
-	}
+	})
+	meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+		Type:               modelv1.ModelConditionDegraded,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            err.Error(),
+		ObservedGeneration: model.Generation,
+	})
 
-	return ctrl.Result{RequeueAfter: 1 * time.Minute}, err
+	// The error is returned through the conditions: controller-runtime ignores RequeueAfter
+	// alongside an error and would retry with its own backoff instead of the configured interval
+	log.FromContext(ctx).Error(err, "Reconcile failed", "reason", reason)
+	return ctrl.Result{RequeueAfter: r.Config.For(model.Namespace).ErrorRequeueInterval}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -503,6 +1286,10 @@ func (r *ModelReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			&modelv1.ModelSource{},
 			handler.EnqueueRequestsFromMapFunc(r.mapModelSourceToModel),
 		).
+		Watches(
+			&modelv1.ClusterModelSource{},
+			handler.EnqueueRequestsFromMapFunc(r.mapClusterModelSourceToModel),
+		).
 		Watches(
 			&datasetv1alpha1.Dataset{},
 			handler.EnqueueRequestsFromMapFunc(r.mapDatasetToModel),
@@ -522,22 +1309,39 @@ func (r *ModelReconciler) mapModelSourceToModel(ctx context.Context, obj client.
 
 	// Find all Models that reference this ModelSource
 	modelList := &modelv1.ModelList{}
-	if err := r.List(ctx, modelList, client.InNamespace(source.Namespace)); err != nil {
+	if err := r.List(ctx, modelList, client.InNamespace(source.Namespace),
+		client.MatchingFields{ModelSourceRefIndex: sourceRefIndexValue(modelv1.SourceKindModelSource, source.Name)}); err != nil {
 		return []reconcile.Request{}
 	}
+	return modelRequests(modelList.Items)
+}
 
-	var requests []reconcile.Request
-	for _, model := range modelList.Items {
-		if model.Spec.SourceRef == source.Name {
-			requests = append(requests, reconcile.Request{
-				NamespacedName: types.NamespacedName{
-					Name:      model.Name,
-					Namespace: model.Namespace,
-				},
-			})
-		}
+func (r *ModelReconciler) mapClusterModelSourceToModel(ctx context.Context, obj client.Object) []reconcile.Request {
+	source, ok := obj.(*modelv1.ClusterModelSource)
+	if !ok {
+		return []reconcile.Request{}
+	}
+
+	// Find all Models in any namespace that reference this ClusterModelSource
+	modelList := &modelv1.ModelList{}
+	if err := r.List(ctx, modelList,
+		client.MatchingFields{ModelSourceRefIndex: sourceRefIndexValue(modelv1.SourceKindClusterModelSource, source.Name)}); err != nil {
+		return []reconcile.Request{}
 	}
+	return modelRequests(modelList.Items)
+}
 
+// modelRequests returns a reconcile request for each Model.
+func modelRequests(models []modelv1.Model) []reconcile.Request {
+	requests := make([]reconcile.Request, 0, len(models))
+	for _, model := range models {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      model.Name,
+				Namespace: model.Namespace,
+			},
+		})
+	}
 	return requests
 }
 
@@ -561,19 +1365,15 @@ func (r *ModelReconciler) mapDatasetToModel(ctx context.Context, obj client.Obje
 		}
 	}
 
-	// Also check labels for REFERENCE Datasets
-	if modelLabel, ok := ds.Labels["modelfs.samzong.dev/model"]; ok {
-		// Format: namespace/name
-		parts := splitModelLabel(modelLabel)
-		if len(parts) == 2 {
-			return []reconcile.Request{
-				{
-					NamespacedName: types.NamespacedName{
-						Name:      parts[1],
-						Namespace: parts[0],
-					},
+	// Also check annotations for REFERENCE Datasets
+	if namespace, name, ok := dataset.ModelOf(ds); ok {
+		return []reconcile.Request{
+			{
+				NamespacedName: types.NamespacedName{
+					Name:      name,
+					Namespace: namespace,
 				},
-			}
+			},
 		}
 	}
 
@@ -588,39 +1388,47 @@ func (r *ModelReconciler) mapNamespaceToModel(ctx context.Context, obj client.Ob
 
 	// Find all Models with sharing enabled
 	modelList := &modelv1.ModelList{}
-	if err := r.List(ctx, modelList); err != nil {
+	if err := r.List(ctx, modelList, client.MatchingFields{ModelShareEnabledIndex: "true"}); err != nil {
 		return []reconcile.Request{}
 	}
 
+	seen := make(map[types.NamespacedName]bool)
 	var requests []reconcile.Request
+	enqueue := func(key types.NamespacedName) {
+		if !seen[key] {
+			seen[key] = true
+			requests = append(requests, reconcile.Request{NamespacedName: key})
+		}
+	}
+
+	// Models with a version whose share now matches the namespace
 	for _, model := range modelList.Items {
 		for _, version := range model.Spec.Versions {
-			if version.Share != nil && version.Share.Enabled {
-				// Check if namespace matches selector
-				if version.Share.NamespaceSelector != nil {
-					selector, err := metav1.LabelSelectorAsSelector(version.Share.NamespaceSelector)
-					if err == nil && selector.Matches(labels.Set(ns.Labels)) {
-						requests = append(requests, reconcile.Request{
-							NamespacedName: types.NamespacedName{
-								Name:      model.Name,
-								Namespace: model.Namespace,
-							},
-						})
-						break
-					}
-				}
+			if version.Share == nil || !version.Share.Enabled {
+				continue
+			}
+			if ok, err := shareMatchesNamespace(version.Share, ns); err == nil && ok {
+				enqueue(types.NamespacedName{Name: model.Name, Namespace: model.Namespace})
+				break
 			}
 		}
 	}
 
-	return requests
-}
-
-func splitModelLabel(s string) []string {
-	for i := 0; i < len(s); i++ {
-		if s[i] == '/' {
-			return []string{s[:i], s[i+1:]}
+	// Models already sharing into the namespace, whose share may have stopped matching; only
+	// Datasets labeled with a model are listed
+	datasetList := &datasetv1alpha1.DatasetList{}
+	if err := r.List(ctx, datasetList, client.InNamespace(ns.Name),
+		client.HasLabels{dataset.LabelModelNamespace, dataset.LabelModel}); err != nil {
+		return requests
+	}
+	for _, ds := range datasetList.Items {
+		if ds.Spec.Source.Type != datasetv1alpha1.DatasetTypeReference {
+			continue
+		}
+		if namespace, name, ok := dataset.ModelOf(&ds); ok && namespace != ns.Name {
+			enqueue(types.NamespacedName{Name: name, Namespace: namespace})
 		}
 	}
-	return []string{s}
+
+	return requests
 }
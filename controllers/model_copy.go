@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	"github.com/samzong/modelfs/pkg/dataset"
+	"github.com/samzong/modelfs/pkg/snapshot"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ShareSnapshotPollInterval is how often a Model with Copy shares is reconciled while a snapshot
+// or a copy is not ready, since VolumeSnapshots are not watched.
+const ShareSnapshotPollInterval = 10 * time.Second
+
+// shareCopySource is the snapshot the Copy shares of a version are restored from.
+type shareCopySource struct {
+	name        string
+	content     *snapshot.Content
+	restoreSize *resource.Quantity
+	// pvc is the source PVC, whose access modes and storage class the copies take
+	pvc *corev1.PersistentVolumeClaim
+}
+
+// ensureShareSnapshot takes a VolumeSnapshot of the active Dataset's PVC once the Dataset is Ready,
+// records its state in the synced version, and returns it once copies can be restored from it.
+// The snapshot of a replaced Dataset is deleted when the new one is taken; copies already restored
+// from it do not depend on it.
+func (r *ModelReconciler) ensureShareSnapshot(ctx context.Context, model *modelv1.Model, version modelv1.ModelVersion, sv *modelv1.SyncedVersion, sourceReady bool) (*shareCopySource, error) {
+	if !sourceReady || sv.PVCName == "" {
+		return nil, nil
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, types.NamespacedName{Name: sv.PVCName, Namespace: model.Namespace}, pvc); err != nil {
+		return nil, fmt.Errorf("get source PVC %s: %w", sv.PVCName, err)
+	}
+
+	name := dataset.GetShareSnapshotName(model.Name, version.Name, sv.ActiveDataset)
+	if previous := sv.ShareSnapshot; previous != nil && previous.Name != name {
+		if err := snapshot.DeleteSnapshot(ctx, r.Client, model.Namespace, previous.Name); err != nil {
+			return nil, fmt.Errorf("delete snapshot %s: %w", previous.Name, err)
+		}
+	}
+	sv.ShareSnapshot = &modelv1.ShareSnapshot{Name: name, SourceDataset: sv.ActiveDataset}
+
+	ownerRef := metav1.NewControllerRef(model, modelv1.GroupVersion.WithKind("Model"))
+	status, err := snapshot.EnsureSnapshot(ctx, r.Client, r.apiReader(), model.Namespace, name, sv.PVCName, version.Share.VolumeSnapshotClassName,
+		shareCopyLabels(model, version.Name), dataset.Annotations(model.Namespace, model.Name, version.Name), ownerRef)
+	if err != nil {
+		sv.ShareSnapshot.Error = err.Error()
+		return nil, err
+	}
+	sv.ShareSnapshot.ReadyToUse = status.ReadyToUse
+	sv.ShareSnapshot.Error = status.Error
+	if !status.ReadyToUse || status.ContentName == "" {
+		return nil, nil
+	}
+
+	content, err := snapshot.GetContent(ctx, r.apiReader(), status.ContentName)
+	if err != nil {
+		return nil, fmt.Errorf("get snapshot content: %w", err)
+	}
+	return &shareCopySource{name: name, content: content, restoreSize: status.RestoreSize, pvc: pvc}, nil
+}
+
+// shareCopy ensures the read-only PVC restored from the share snapshot in a granted target
+// namespace and records it on target. A copy of a replaced Dataset is kept until the snapshot of the new one is
+// ready, then deleted and restored again. Without a ready snapshot the target is SharePending.
+func (r *ModelReconciler) shareCopy(ctx context.Context, model *modelv1.Model, versionName, ns string, source *shareCopySource, target *modelv1.ShareTarget) error {
+	name := dataset.GetReferenceDatasetName(r.Config.For(ns).ShareNamePrefix, model.Namespace, model.Name, versionName)
+	target.PVCName = name
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, pvc)
+	switch {
+	case err == nil:
+		if pvc.Labels[dataset.LabelShareCopy] != "true" || !dataset.IsManagedBy(pvc, model.Namespace, model.Name, versionName) {
+			return fmt.Errorf("PVC %s/%s exists and is not a copy of version %s", ns, name, versionName)
+		}
+		target.Phase = string(pvc.Status.Phase)
+		if !pvc.DeletionTimestamp.IsZero() {
+			target.State = modelv1.ShareTargetSharePending
+			return nil
+		}
+		// A writable copy restored before copies were read-only is restored again
+		if !snapshot.IsReadOnly(pvc) {
+			if err := r.deleteShareCopy(ctx, ns, name); err != nil {
+				return fmt.Errorf("delete copy in %s: %w", ns, err)
+			}
+			target.State = modelv1.ShareTargetSharePending
+			return nil
+		}
+		if source == nil || pvc.Annotations[dataset.AnnotationSourceSnapshot] == source.name {
+			return r.checkShareCopy(ctx, pvc)
+		}
+		if err := r.deleteShareCopy(ctx, ns, name); err != nil {
+			return fmt.Errorf("delete copy in %s: %w", ns, err)
+		}
+		target.State = modelv1.ShareTargetSharePending
+		return nil
+	case !errors.IsNotFound(err):
+		return fmt.Errorf("get copy in %s: %w", ns, err)
+	}
+
+	if source == nil {
+		target.State = modelv1.ShareTargetSharePending
+		return nil
+	}
+
+	labels := shareCopyLabels(model, versionName)
+	annotations := dataset.Annotations(model.Namespace, model.Name, versionName)
+	annotations[dataset.AnnotationSourceSnapshot] = source.name
+	status, err := snapshot.EnsureImportedSnapshot(ctx, r.Client, r.apiReader(), ns, name, dataset.GetShareSnapshotContentName(ns, name), source.content, labels, annotations)
+	if err != nil {
+		return fmt.Errorf("import snapshot into %s: %w", ns, err)
+	}
+	if status.Terminating {
+		// The imported snapshot of a replaced copy is still being deleted
+		if err := r.deleteShareCopy(ctx, ns, name); err != nil {
+			return fmt.Errorf("delete copy in %s: %w", ns, err)
+		}
+		target.State = modelv1.ShareTargetSharePending
+		return nil
+	}
+
+	restored, err := snapshot.EnsureRestoredPVC(ctx, r.Client, r.apiReader(), ns, name, source.pvc, source.restoreSize, labels, annotations)
+	if err != nil {
+		return fmt.Errorf("restore copy in %s: %w", ns, err)
+	}
+	target.Phase = string(restored.Status.Phase)
+	return r.checkShareCopy(ctx, restored)
+}
+
+// checkShareCopy fails a copy whose PVC cannot be provisioned, e.g. because the CSI driver cannot
+// restore the snapshot ReadOnlyMany; a writable copy is never handed out instead.
+func (r *ModelReconciler) checkShareCopy(ctx context.Context, pvc *corev1.PersistentVolumeClaim) error {
+	message, err := snapshot.ProvisioningError(ctx, r.apiReader(), pvc)
+	if err != nil {
+		// The copy is reported pending until its events can be read
+		log.FromContext(ctx).Error(err, "Listing events of copy failed", "pvc", pvc.Namespace+"/"+pvc.Name)
+		return nil
+	}
+	if message != "" {
+		return fmt.Errorf("copy %s/%s cannot be restored ReadOnlyMany: %s", pvc.Namespace, pvc.Name, message)
+	}
+	return nil
+}
+
+// deleteShareCopiesExcept deletes the copies of a version outside the keep namespaces.
+func (r *ModelReconciler) deleteShareCopiesExcept(ctx context.Context, model *modelv1.Model, versionName string, keep map[string]bool) error {
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := r.List(ctx, pvcList, client.MatchingLabels(shareCopyLabels(model, ""))); err != nil {
+		return err
+	}
+	for _, pvc := range pvcList.Items {
+		if keep[pvc.Namespace] || !dataset.IsManagedBy(&pvc, model.Namespace, model.Name, versionName) {
+			continue
+		}
+		if err := r.deleteShareCopy(ctx, pvc.Namespace, pvc.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteShareSnapshot deletes the snapshot Copy shares of a version were restored from.
+func (r *ModelReconciler) deleteShareSnapshot(ctx context.Context, model *modelv1.Model, sv *modelv1.SyncedVersion) error {
+	if sv.ShareSnapshot == nil {
+		return nil
+	}
+	if err := snapshot.DeleteSnapshot(ctx, r.Client, model.Namespace, sv.ShareSnapshot.Name); err != nil {
+		return err
+	}
+	sv.ShareSnapshot = nil
+	return nil
+}
+
+// deleteShareCopy deletes a copy: its PVC, imported VolumeSnapshot and VolumeSnapshotContent.
+func (r *ModelReconciler) deleteShareCopy(ctx context.Context, namespace, name string) error {
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if err := r.Delete(ctx, pvc); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err := snapshot.DeleteSnapshot(ctx, r.Client, namespace, name); err != nil {
+		return err
+	}
+	return snapshot.DeleteContent(ctx, r.Client, dataset.GetShareSnapshotContentName(namespace, name))
+}
+
+// nextShareSnapshotPoll returns ShareSnapshotPollInterval while a Copy share waits for a snapshot, or 0.
+func nextShareSnapshotPoll(model *modelv1.Model) time.Duration {
+	for i := range model.Status.SyncedVersions {
+		sv := &model.Status.SyncedVersions[i]
+		if sv.ShareSnapshot == nil {
+			continue
+		}
+		if !sv.ShareSnapshot.ReadyToUse {
+			return ShareSnapshotPollInterval
+		}
+		for _, target := range sv.ShareTargets {
+			if target.State == modelv1.ShareTargetSharePending {
+				return ShareSnapshotPollInterval
+			}
+		}
+	}
+	return 0
+}
+
+// shareCopyLabels returns the labels of the snapshots and PVCs of a version's Copy shares.
+func shareCopyLabels(model *modelv1.Model, versionName string) map[string]string {
+	labels := dataset.Labels(model.Namespace, model.Name, versionName)
+	labels[dataset.LabelShareCopy] = "true"
+	return labels
+}
+
+func (r *ModelReconciler) apiReader() client.Reader {
+	if r.APIReader == nil {
+		return r.Client
+	}
+	return r.APIReader
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	datasetv1alpha1 "github.com/BaizeAI/dataset/api/dataset/v1alpha1"
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	"github.com/samzong/modelfs/pkg/dataset"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultDeletionCleanupTimeout is how long a deleted Model waits for its cleanup before the
+	// ForceReleaseAnnotation is honored, when no timeout is configured.
+	DefaultDeletionCleanupTimeout = time.Hour
+	// DeletionCleanupPollInterval is how often a deleted Model checks its cleanup, since the PVCs
+	// of Copy shares are not watched.
+	DeletionCleanupPollInterval = 30 * time.Second
+
+	// maxListedBlockers caps the objects named in the Terminating condition.
+	maxListedBlockers = 10
+)
+
+// cleanupModel deletes what a deleted Model leaves behind and returns what still exists. The
+// REFERENCE Datasets and copies in other namespaces go first; the Model's own Datasets are only
+// deleted once nothing references them. Datasets of ModelClaims are withdrawn by the claim
+// controller but are waited for as well.
+func (r *ModelReconciler) cleanupModel(ctx context.Context, model *modelv1.Model) ([]string, error) {
+	if err := r.deleteReferenceDatasets(ctx, model); err != nil {
+		return nil, fmt.Errorf("delete reference datasets: %w", err)
+	}
+	if err := r.deleteShareCopiesExcept(ctx, model, "", nil); err != nil {
+		return nil, fmt.Errorf("delete copies: %w", err)
+	}
+
+	datasetList := &datasetv1alpha1.DatasetList{}
+	if err := r.List(ctx, datasetList, client.MatchingFields{DatasetModelIndex: formatNamespacedName(model.Namespace, model.Name)}); err != nil {
+		return nil, err
+	}
+	var blockers []string
+	for _, ds := range datasetList.Items {
+		if ds.Spec.Source.Type == datasetv1alpha1.DatasetTypeReference && dataset.IsManagedBy(&ds, model.Namespace, model.Name, "") {
+			blockers = append(blockers, fmt.Sprintf("Dataset %s/%s", ds.Namespace, ds.Name))
+		}
+	}
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := r.List(ctx, pvcList, client.MatchingLabels(shareCopyLabels(model, ""))); err != nil {
+		return nil, err
+	}
+	for _, pvc := range pvcList.Items {
+		if dataset.IsManagedBy(&pvc, model.Namespace, model.Name, "") {
+			blockers = append(blockers, fmt.Sprintf("PVC %s/%s", pvc.Namespace, pvc.Name))
+		}
+	}
+	if len(blockers) > 0 {
+		return blockers, nil
+	}
+
+	if err := r.deleteMainDatasets(ctx, model); err != nil {
+		return nil, fmt.Errorf("delete main datasets: %w", err)
+	}
+	// Datasets just deleted or orphaned must not be reported from the cache, so what remains is
+	// read from the API server, which selects them by the model labels
+	remaining := &datasetv1alpha1.DatasetList{}
+	if err := r.apiReader().List(ctx, remaining, client.InNamespace(model.Namespace),
+		client.MatchingLabels(dataset.Labels(model.Namespace, model.Name, ""))); err != nil {
+		return nil, err
+	}
+	for _, ds := range remaining.Items {
+		if metav1.IsControlledBy(&ds, model) {
+			blockers = append(blockers, fmt.Sprintf("Dataset %s/%s", ds.Namespace, ds.Name))
+		}
+	}
+	return blockers, nil
+}
+
+// setTerminatingCondition reports what keeps a deleted Model from being released. Once the
+// cleanup timeout has passed, the message says how to force the release.
+func setTerminatingCondition(status *modelv1.ModelStatus, model *modelv1.Model, blockers []string, cleanupErr error, timeout time.Duration, timedOut bool) {
+	condition := metav1.Condition{
+		Type:               modelv1.ModelConditionTerminating,
+		Status:             metav1.ConditionTrue,
+		Reason:             modelv1.ReasonCleanupPending,
+		ObservedGeneration: model.Generation,
+	}
+	if cleanupErr != nil {
+		condition.Reason = modelv1.ReasonCleanupFailed
+		condition.Message = fmt.Sprintf("Cleanup failed: %v", cleanupErr)
+	} else {
+		condition.Message = fmt.Sprintf("Waiting for deletion of %s", summarizeBlockers(blockers))
+	}
+	if timedOut {
+		condition.Reason = modelv1.ReasonCleanupTimedOut
+		condition.Message = fmt.Sprintf("%s; cleanup did not finish within %s, set annotation %s=true to release the Model anyway",
+			condition.Message, timeout, modelv1.ForceReleaseAnnotation)
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+// summarizeBlockers lists the first maxListedBlockers blockers in order and counts the rest.
+func summarizeBlockers(blockers []string) string {
+	sorted := append([]string(nil), blockers...)
+	sort.Strings(sorted)
+	if extra := len(sorted) - maxListedBlockers; extra > 0 {
+		return fmt.Sprintf("%s and %d more", strings.Join(sorted[:maxListedBlockers], ", "), extra)
+	}
+	return strings.Join(sorted, ", ")
+}
+
+func (r *ModelReconciler) deletionCleanupTimeout() time.Duration {
+	if r.DeletionCleanupTimeout <= 0 {
+		return DefaultDeletionCleanupTimeout
+	}
+	return r.DeletionCleanupTimeout
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	datasetv1alpha1 "github.com/BaizeAI/dataset/api/dataset/v1alpha1"
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	"github.com/samzong/modelfs/pkg/dataset"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// adoptVersionDataset makes an existing Dataset of the model the active Dataset of a version
+// that has none recorded, so it is updated in place instead of synced again under a new name.
+// It looks for a Dataset annotated with the version, including one the Orphan deletion policy
+// detached, then for the names used before names were bounded: the hashed name for the current
+// spec, given the hash those releases computed, and the unhashed mdl-<model>-<version>.
+func (r *ModelReconciler) adoptVersionDataset(ctx context.Context, model *modelv1.Model, versionName, prefix, legacyHash string, sv *modelv1.SyncedVersion) error {
+	datasetList := &datasetv1alpha1.DatasetList{}
+	if err := r.List(ctx, datasetList, client.InNamespace(model.Namespace),
+		client.MatchingFields{DatasetModelIndex: formatNamespacedName(model.Namespace, model.Name)}); err != nil {
+		return err
+	}
+	var adopted *datasetv1alpha1.Dataset
+	for i := range datasetList.Items {
+		ds := &datasetList.Items[i]
+		if !(metav1.IsControlledBy(ds, model) || isOrphanedDataset(ds)) || !dataset.IsManagedBy(ds, model.Namespace, model.Name, versionName) {
+			continue
+		}
+		// Prefer a Dataset that already finished syncing
+		if adopted == nil || ds.Status.Phase == datasetv1alpha1.DatasetStatusPhaseReady {
+			adopted = ds
+		}
+	}
+
+	if adopted == nil {
+		for _, name := range []string{
+			dataset.GetLegacyVersionDatasetName(prefix, model.Name, versionName, legacyHash),
+			dataset.GetDatasetName(model.Name, versionName),
+		} {
+			ds := &datasetv1alpha1.Dataset{}
+			if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: model.Namespace}, ds); err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return err
+			}
+			if metav1.IsControlledBy(ds, model) {
+				adopted = ds
+				break
+			}
+		}
+	}
+
+	if adopted == nil {
+		return nil
+	}
+	// A Dataset kept by the Orphan policy is owned by the Model again once it is applied
+	if isOrphanedDataset(adopted) {
+		patch := client.MergeFrom(adopted.DeepCopy())
+		delete(adopted.Labels, dataset.LabelRetained)
+		if err := r.Patch(ctx, adopted, patch); err != nil {
+			return err
+		}
+		sv.OrphanedDataset = ""
+	}
+	sv.ActiveDataset = adopted.Name
+	return nil
+}
+
+// shareDatasetName returns the name of the REFERENCE Dataset that shares a version into
+// targetNs: an existing one annotated with the version, one under the legacy unhashed name
+// that references the model namespace, or else the name for a new one.
+func (r *ModelReconciler) shareDatasetName(ctx context.Context, model *modelv1.Model, versionName, targetNs string) (string, error) {
+	datasetList := &datasetv1alpha1.DatasetList{}
+	if err := r.List(ctx, datasetList, client.InNamespace(targetNs),
+		client.MatchingFields{DatasetModelIndex: formatNamespacedName(model.Namespace, model.Name)}); err != nil {
+		return "", err
+	}
+	for i := range datasetList.Items {
+		ds := &datasetList.Items[i]
+		if ds.Spec.Source.Type == datasetv1alpha1.DatasetTypeReference && !isClaimDataset(ds) && dataset.IsManagedBy(ds, model.Namespace, model.Name, versionName) {
+			return ds.Name, nil
+		}
+	}
+
+	prefix := r.Config.For(targetNs).ShareNamePrefix
+	legacyName := dataset.GetLegacyReferenceDatasetName(prefix, model.Namespace, model.Name, versionName)
+	legacy := &datasetv1alpha1.Dataset{}
+	err := r.Get(ctx, types.NamespacedName{Name: legacyName, Namespace: targetNs}, legacy)
+	switch {
+	case err == nil:
+		if legacy.Spec.Source.Type == datasetv1alpha1.DatasetTypeReference &&
+			strings.HasPrefix(legacy.Spec.Source.URI, "dataset://"+model.Namespace+"/") &&
+			(len(legacy.Annotations[dataset.AnnotationModel]) == 0 || dataset.IsManagedBy(legacy, model.Namespace, model.Name, versionName)) {
+			return legacyName, nil
+		}
+	case !errors.IsNotFound(err):
+		return "", err
+	}
+
+	return dataset.GetReferenceDatasetName(prefix, model.Namespace, model.Name, versionName), nil
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	"github.com/samzong/modelfs/pkg/config"
+	"github.com/samzong/modelfs/pkg/upstream"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PreflightRetryInterval is how long a version that failed its upstream check waits before it is checked again.
+	PreflightRetryInterval = 5 * time.Minute
+)
+
+// preflightVersion checks the repo and revision of a version through the source API before
+// any Dataset, and so any PVC, is created for them. A result is kept per "repo@revision";
+// a failed check is repeated after PreflightRetryInterval, e.g. once gated access is granted.
+// It reports whether the version may proceed. A check that fails without a verdict is recorded
+// as CheckUnavailable, repeated on the next reconcile, and returned as an error.
+func (r *ModelReconciler) preflightVersion(ctx context.Context, source *modelSource, version modelv1.ModelVersion, sv *modelv1.SyncedVersion) (bool, error) {
+	revision := version.Revision
+	if revision == "" {
+		revision = config.DefaultRevision
+	}
+	from := version.Repo + "@" + revision
+	now := time.Now()
+
+	if previous := sv.Preflight; previous != nil && previous.From == from {
+		if previous.Reason == modelv1.ReasonPreflightPassed {
+			return true, nil
+		}
+		if previous.Reason != modelv1.ReasonCheckUnavailable && now.Before(previous.CheckedAt.Add(PreflightRetryInterval)) {
+			return false, nil
+		}
+	}
+
+	creds, err := r.sourceCredentials(ctx, source)
+	if err != nil {
+		return false, err
+	}
+	err = r.Preflighter.CheckAccess(ctx, source.Spec, creds, version.Repo, revision)
+	if errors.Is(err, upstream.ErrUnsupported) {
+		sv.Preflight = nil
+		return true, nil
+	}
+
+	reason := preflightReason(err)
+	message := fmt.Sprintf("%s is available", from)
+	if err != nil {
+		message = err.Error()
+	}
+	sv.Preflight = &modelv1.PreflightResult{
+		From:      from,
+		Reason:    reason,
+		Message:   message,
+		CheckedAt: metav1.NewTime(now),
+	}
+	if reason == modelv1.ReasonCheckUnavailable {
+		// Transient failures are retried instead of blocking the version
+		return false, err
+	}
+	return err == nil, nil
+}
+
+// preflightReason maps a preflight error to the reason recorded on the version, or
+// CheckUnavailable if the error does not tell anything about the repo or revision.
+func preflightReason(err error) string {
+	switch {
+	case err == nil:
+		return modelv1.ReasonPreflightPassed
+	case errors.Is(err, upstream.ErrRepoNotFound):
+		return modelv1.ReasonRepoNotFound
+	case errors.Is(err, upstream.ErrRevisionNotFound):
+		return modelv1.ReasonRevisionNotFound
+	case errors.Is(err, upstream.ErrAccessGated):
+		return modelv1.ReasonAccessGated
+	case errors.Is(err, upstream.ErrUnauthorized):
+		return modelv1.ReasonUnauthorized
+	default:
+		return modelv1.ReasonCheckUnavailable
+	}
+}
+
+// preflightFailed reports whether the last upstream check of a version failed the repo or revision.
+func preflightFailed(sv *modelv1.SyncedVersion) bool {
+	return sv.Preflight != nil && sv.Preflight.Reason != modelv1.ReasonPreflightPassed && sv.Preflight.Reason != modelv1.ReasonCheckUnavailable
+}
+
+// nextPreflightRetry returns how long until the next failed or unavailable check of a version is due
+// to be repeated, or 0 if there is none.
+func nextPreflightRetry(model *modelv1.Model, now time.Time) time.Duration {
+	var next time.Duration
+	for i := range model.Status.SyncedVersions {
+		sv := &model.Status.SyncedVersions[i]
+		if sv.Preflight == nil || sv.Preflight.Reason == modelv1.ReasonPreflightPassed {
+			continue
+		}
+		wait := sv.Preflight.CheckedAt.Add(PreflightRetryInterval).Sub(now)
+		if wait < time.Second {
+			wait = time.Second
+		}
+		if next == 0 || wait < next {
+			next = wait
+		}
+	}
+	return next
+}
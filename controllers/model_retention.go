@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	datasetv1alpha1 "github.com/BaizeAI/dataset/api/dataset/v1alpha1"
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	"github.com/samzong/modelfs/pkg/dataset"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// deletionPolicy returns the deletion policy of a version, falling back to the Model's and then to Delete.
+func deletionPolicy(model *modelv1.Model, versionName string) modelv1.DeletionPolicy {
+	for _, version := range model.Spec.Versions {
+		if version.Name == versionName && version.DeletionPolicy != "" {
+			return version.DeletionPolicy
+		}
+	}
+	if model.Spec.DeletionPolicy != "" {
+		return model.Spec.DeletionPolicy
+	}
+	return modelv1.DeletionPolicyDelete
+}
+
+// retainVolume keeps the PersistentVolume bound to a Dataset's PVC when the Dataset is deleted:
+// its reclaim policy is switched to Retain, the previous one recorded, and it is labeled with the
+// model version so a re-added version finds it. It returns the volume name, or "" if the PVC was
+// never bound and there is nothing to keep.
+func (r *ModelReconciler) retainVolume(ctx context.Context, model *modelv1.Model, ds *datasetv1alpha1.Dataset) (string, error) {
+	pvcName := ds.Status.PVCName
+	if pvcName == "" {
+		pvcName = ds.Spec.VolumeClaimTemplate.Name
+	}
+	if pvcName == "" {
+		pvcName = ds.Name
+	}
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, types.NamespacedName{Name: pvcName, Namespace: ds.Namespace}, pvc); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	if pvc.Spec.VolumeName == "" {
+		return "", nil
+	}
+	pv := &corev1.PersistentVolume{}
+	if err := r.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+
+	versionName := ds.Annotations[dataset.AnnotationVersion]
+	patch := client.MergeFrom(pv.DeepCopy())
+	if pv.Labels == nil {
+		pv.Labels = map[string]string{}
+	}
+	for key, value := range dataset.Labels(model.Namespace, model.Name, versionName) {
+		pv.Labels[key] = value
+	}
+	pv.Labels[dataset.LabelRetained] = "true"
+	if pv.Annotations == nil {
+		pv.Annotations = map[string]string{}
+	}
+	for key, value := range dataset.Annotations(model.Namespace, model.Name, versionName) {
+		pv.Annotations[key] = value
+	}
+	if _, ok := pv.Annotations[dataset.AnnotationReclaimPolicy]; !ok {
+		pv.Annotations[dataset.AnnotationReclaimPolicy] = string(pv.Spec.PersistentVolumeReclaimPolicy)
+	}
+	pv.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+	if err := r.Patch(ctx, pv, patch); err != nil {
+		return "", fmt.Errorf("retain volume %s: %w", pv.Name, err)
+	}
+	return pv.Name, nil
+}
+
+// orphanDataset detaches a Dataset from the Model and labels it retained, so it survives the
+// version and the Model and a re-added version adopts it again.
+func (r *ModelReconciler) orphanDataset(ctx context.Context, model *modelv1.Model, ds *datasetv1alpha1.Dataset) error {
+	patch := client.MergeFrom(ds.DeepCopy())
+	var ownerRefs []metav1.OwnerReference
+	for _, ref := range ds.OwnerReferences {
+		if ref.UID != model.UID {
+			ownerRefs = append(ownerRefs, ref)
+		}
+	}
+	ds.OwnerReferences = ownerRefs
+	if ds.Labels == nil {
+		ds.Labels = map[string]string{}
+	}
+	ds.Labels[dataset.LabelRetained] = "true"
+	if err := r.Patch(ctx, ds, patch); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("orphan dataset %s: %w", ds.Name, err)
+	}
+	return nil
+}
+
+// isOrphanedDataset reports whether a Dataset was detached by the Orphan deletion policy.
+func isOrphanedDataset(ds *datasetv1alpha1.Dataset) bool {
+	return ds.Labels[dataset.LabelRetained] == "true" && metav1.GetControllerOf(ds) == nil
+}
+
+// retainedVolumeTemplate points the PVC template of a new Dataset for a version at the most
+// recent volume the Retain policy kept for it, taking the volume's storage class, access modes
+// and capacity so the PVC can bind to it. A Dataset that exists keeps the retained volume it was
+// bound to; a volume name set on it by anyone else is left alone.
+func (r *ModelReconciler) retainedVolumeTemplate(ctx context.Context, model *modelv1.Model, versionName string, spec *datasetv1alpha1.DatasetSpec, existing *datasetv1alpha1.Dataset) error {
+	if existing != nil {
+		template := existing.Spec.VolumeClaimTemplate.Spec
+		if template.VolumeName == "" {
+			return nil
+		}
+		pv := &corev1.PersistentVolume{}
+		if err := r.Get(ctx, types.NamespacedName{Name: template.VolumeName}, pv); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if !dataset.IsManagedBy(pv, model.Namespace, model.Name, versionName) {
+			return nil
+		}
+		setVolumeClaimFields(&spec.VolumeClaimTemplate.Spec, template.VolumeName, template.StorageClassName,
+			template.AccessModes, template.VolumeMode, template.Resources.Requests[corev1.ResourceStorage])
+		return nil
+	}
+
+	labels := dataset.Labels(model.Namespace, model.Name, versionName)
+	labels[dataset.LabelRetained] = "true"
+	pvList := &corev1.PersistentVolumeList{}
+	if err := r.List(ctx, pvList, client.MatchingLabels(labels)); err != nil {
+		return err
+	}
+	var retained *corev1.PersistentVolume
+	for i := range pvList.Items {
+		pv := &pvList.Items[i]
+		if !dataset.IsManagedBy(pv, model.Namespace, model.Name, versionName) {
+			continue
+		}
+		// A bound volume already serves another claim
+		if pv.Status.Phase != corev1.VolumeReleased && pv.Status.Phase != corev1.VolumeAvailable {
+			continue
+		}
+		if retained == nil || retained.CreationTimestamp.Before(&pv.CreationTimestamp) {
+			retained = pv
+		}
+	}
+	if retained == nil {
+		return nil
+	}
+
+	storageClassName := retained.Spec.StorageClassName
+	setVolumeClaimFields(&spec.VolumeClaimTemplate.Spec, retained.Name, &storageClassName,
+		retained.Spec.AccessModes, retained.Spec.VolumeMode, retained.Spec.Capacity[corev1.ResourceStorage])
+	return nil
+}
+
+// setVolumeClaimFields sets the fields of a PVC template that bind it to a retained volume.
+func setVolumeClaimFields(template *corev1.PersistentVolumeClaimSpec, volumeName string, storageClassName *string, accessModes []corev1.PersistentVolumeAccessMode, volumeMode *corev1.PersistentVolumeMode, storage resource.Quantity) {
+	template.VolumeName = volumeName
+	template.StorageClassName = storageClassName
+	template.AccessModes = accessModes
+	template.VolumeMode = volumeMode
+	template.Resources = corev1.VolumeResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceStorage: storage},
+	}
+}
+
+// claimRetainedVolume hands a retained volume to the PVC of the Dataset that adopted it. The volume
+// is first pre-bound to the PVC; once the PVC is bound, its reclaim policy is restored and it loses
+// the retained label.
+func (r *ModelReconciler) claimRetainedVolume(ctx context.Context, model *modelv1.Model, sv *modelv1.SyncedVersion, volumeName, pvcName string) error {
+	pv := &corev1.PersistentVolume{}
+	if err := r.Get(ctx, types.NamespacedName{Name: volumeName}, pv); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if pv.Labels[dataset.LabelRetained] != "true" {
+		return nil
+	}
+
+	patch := client.MergeFrom(pv.DeepCopy())
+	switch ref := pv.Spec.ClaimRef; {
+	case ref == nil || ref.Namespace != model.Namespace || ref.Name != pvcName:
+		// Dropping the UID of the deleted PVC releases the volume to the new one
+		pv.Spec.ClaimRef = &corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "PersistentVolumeClaim",
+			Namespace:  model.Namespace,
+			Name:       pvcName,
+		}
+	case pv.Status.Phase == corev1.VolumeBound:
+		if policy := pv.Annotations[dataset.AnnotationReclaimPolicy]; policy != "" {
+			pv.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimPolicy(policy)
+		}
+		delete(pv.Labels, dataset.LabelRetained)
+		delete(pv.Annotations, dataset.AnnotationReclaimPolicy)
+		sv.RetainedVolume = ""
+	default:
+		return nil
+	}
+	if err := r.Patch(ctx, pv, patch); err != nil {
+		return fmt.Errorf("claim retained volume %s: %w", pv.Name, err)
+	}
+	return nil
+}
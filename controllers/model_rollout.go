@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	datasetv1alpha1 "github.com/BaizeAI/dataset/api/dataset/v1alpha1"
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultRetiredDatasetGracePeriod is how long a replaced Dataset is kept when no grace period is configured.
+	DefaultRetiredDatasetGracePeriod = 10 * time.Minute
+)
+
+// rolloutVersionDataset tracks the Dataset synced for a changed version spec.
+// The first Dataset of a version becomes active right away. Later ones stay pending
+// until Ready, then replace the active Dataset, which is retired and deleted after
+// the grace period so consumers still mounting its PVC are not cut off mid-read.
+func (r *ModelReconciler) rolloutVersionDataset(ctx context.Context, model *modelv1.Model, sv *modelv1.SyncedVersion, datasetName, versionHash string, existing *datasetv1alpha1.Dataset) error {
+	// The Dataset may be one retired earlier if the spec was reverted
+	sv.RetiringDatasets = removeRetiringDataset(sv.RetiringDatasets, datasetName)
+
+	if sv.ActiveDataset == "" {
+		sv.ActiveDataset = datasetName
+		sv.ObservedVersionHash = versionHash
+		sv.PendingDataset = ""
+		return nil
+	}
+
+	// Replace a pending Dataset of an older spec that never became active
+	if sv.PendingDataset != "" && sv.PendingDataset != datasetName {
+		if err := r.deleteDatasetByName(ctx, model.Namespace, sv.PendingDataset); err != nil {
+			return err
+		}
+	}
+	sv.PendingDataset = datasetName
+
+	if existing == nil || existing.Status.Phase != datasetv1alpha1.DatasetStatusPhaseReady {
+		return nil
+	}
+
+	// Flip to the new Dataset and retire the old one
+	sv.RetiringDatasets = append(sv.RetiringDatasets, modelv1.RetiringDataset{
+		Name:      sv.ActiveDataset,
+		RetiredAt: metav1.Now(),
+	})
+	sv.ActiveDataset = datasetName
+	sv.ObservedVersionHash = versionHash
+	sv.PendingDataset = ""
+	return nil
+}
+
+// abandonPendingDataset deletes a pending Dataset that is no longer wanted,
+// e.g. because the version spec was changed back to the active one.
+func (r *ModelReconciler) abandonPendingDataset(ctx context.Context, model *modelv1.Model, sv *modelv1.SyncedVersion) error {
+	if sv.PendingDataset == "" || sv.PendingDataset == sv.ActiveDataset {
+		sv.PendingDataset = ""
+		return nil
+	}
+	if err := r.deleteDatasetByName(ctx, model.Namespace, sv.PendingDataset); err != nil {
+		return err
+	}
+	sv.PendingDataset = ""
+	return nil
+}
+
+// gcRetiredDatasets deletes retired Datasets whose grace period has ended.
+func (r *ModelReconciler) gcRetiredDatasets(ctx context.Context, model *modelv1.Model, sv *modelv1.SyncedVersion, now time.Time) error {
+	var keep []modelv1.RetiringDataset
+	for _, retiring := range sv.RetiringDatasets {
+		if now.Before(retiring.RetiredAt.Add(r.retiredDatasetGracePeriod())) {
+			keep = append(keep, retiring)
+			continue
+		}
+		if err := r.deleteDatasetByName(ctx, model.Namespace, retiring.Name); err != nil {
+			return err
+		}
+	}
+	sv.RetiringDatasets = keep
+	return nil
+}
+
+// nextRetiredDatasetGC returns how long until the next retired Dataset is due for deletion, or 0 if there is none.
+func (r *ModelReconciler) nextRetiredDatasetGC(model *modelv1.Model, now time.Time) time.Duration {
+	var next time.Duration
+	for _, sv := range model.Status.SyncedVersions {
+		for _, retiring := range sv.RetiringDatasets {
+			wait := retiring.RetiredAt.Add(r.retiredDatasetGracePeriod()).Sub(now)
+			if wait < time.Second {
+				wait = time.Second
+			}
+			if next == 0 || wait < next {
+				next = wait
+			}
+		}
+	}
+	return next
+}
+
+func (r *ModelReconciler) retiredDatasetGracePeriod() time.Duration {
+	if r.RetiredDatasetGracePeriod <= 0 {
+		return DefaultRetiredDatasetGracePeriod
+	}
+	return r.RetiredDatasetGracePeriod
+}
+
+func (r *ModelReconciler) deleteDatasetByName(ctx context.Context, namespace, name string) error {
+	ds := &datasetv1alpha1.Dataset{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	if err := r.Delete(ctx, ds); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func removeRetiringDataset(retiring []modelv1.RetiringDataset, name string) []modelv1.RetiringDataset {
+	var result []modelv1.RetiringDataset
+	for _, item := range retiring {
+		if item.Name != name {
+			result = append(result, item)
+		}
+	}
+	return result
+}
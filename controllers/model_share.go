@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	datasetv1alpha1 "github.com/BaizeAI/dataset/api/dataset/v1alpha1"
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MaxShareHistory is the number of share events kept per version.
+const MaxShareHistory = 20
+
+// grantShare returns the share target of a namespace matched by a version's share: PendingApproval
+// until the model owner approves it, Shared until it expires, then Expired. A grant is kept across
+// reconciles so expiry counts from the first grant; approvals, revocations and expiries are
+// recorded in the share history of the synced version.
+func grantShare(model *modelv1.Model, version modelv1.ModelVersion, sv *modelv1.SyncedVersion, namespace string, now metav1.Time) modelv1.ShareTarget {
+	var previous *modelv1.ShareTarget
+	for i := range sv.ShareTargets {
+		if sv.ShareTargets[i].Namespace == namespace {
+			previous = &sv.ShareTargets[i]
+			break
+		}
+	}
+
+	target := modelv1.ShareTarget{Namespace: namespace}
+	if !shareApproved(model, version.Name, version.Share, namespace) {
+		if previous != nil && previous.GrantedAt != nil {
+			recordShareEvent(sv, namespace, modelv1.ShareEventRevoked, now)
+		}
+		target.State = modelv1.ShareTargetPendingApproval
+		return target
+	}
+
+	if previous != nil && previous.GrantedAt != nil {
+		target.GrantedAt = previous.GrantedAt
+	} else {
+		target.GrantedAt = &now
+		if version.Share.Approval == modelv1.ShareApprovalManual {
+			recordShareEvent(sv, namespace, modelv1.ShareEventApproved, now)
+		}
+	}
+
+	target.ExpiresAt = shareExpiry(version.Share, *target.GrantedAt)
+	if target.ExpiresAt != nil && !now.Before(target.ExpiresAt) {
+		if previous == nil || previous.State != modelv1.ShareTargetExpired {
+			recordShareEvent(sv, namespace, modelv1.ShareEventExpired, now)
+		}
+		target.State = modelv1.ShareTargetExpired
+		return target
+	}
+	target.State = modelv1.ShareTargetShared
+	return target
+}
+
+// datasetReady reports whether a Dataset exists and is Ready.
+func datasetReady(ctx context.Context, c client.Reader, namespace, name string) (bool, error) {
+	ds := &datasetv1alpha1.Dataset{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, ds); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return ds.Status.Phase == datasetv1alpha1.DatasetStatusPhaseReady, nil
+}
+
+// referenceWaits reports whether the REFERENCE Dataset namespace/name must wait before it is
+// applied with sourceURI: while it does not exist and the source is not Ready, so consumers never
+// mount a source that is still syncing or failed, and while it references a replaced source
+// Dataset. A reference stays bound to the PVC of the Dataset it was created for, so one that
+// owned accepts is deleted and created again rather than pointed at the new Dataset.
+// An existing reference to the current source is kept while the source resyncs.
+func referenceWaits(ctx context.Context, c client.Client, namespace, name, sourceURI string, sourceReady bool, owned func(*datasetv1alpha1.Dataset) bool) (bool, error) {
+	current := &datasetv1alpha1.Dataset{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return false, err
+		}
+		return !sourceReady, nil
+	}
+	if !current.DeletionTimestamp.IsZero() {
+		return true, nil
+	}
+	if current.Spec.Source.Type != datasetv1alpha1.DatasetTypeReference || current.Spec.Source.URI == sourceURI || !owned(current) {
+		return false, nil
+	}
+	if err := c.Delete(ctx, current); err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	return true, nil
+}
+
+// shareApproved reports whether a share may reach a namespace: always without Manual approval,
+// otherwise when the Model's approval annotation for the version lists the namespace.
+func shareApproved(model *modelv1.Model, versionName string, share *modelv1.ShareSpec, namespace string) bool {
+	if share.Approval != modelv1.ShareApprovalManual {
+		return true
+	}
+	for _, approved := range strings.Split(model.Annotations[modelv1.ShareApprovalAnnotationPrefix+versionName], ",") {
+		if strings.TrimSpace(approved) == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// shareExpiry returns when a share granted at grantedAt expires, the earlier of expiresAt and
+// grantedAt plus expiresAfter, or nil if it does not expire.
+func shareExpiry(share *modelv1.ShareSpec, grantedAt metav1.Time) *metav1.Time {
+	var expiresAt *metav1.Time
+	if share.ExpiresAt != nil {
+		expiresAt = share.ExpiresAt.DeepCopy()
+	}
+	if share.ExpiresAfter != nil {
+		after := metav1.NewTime(grantedAt.Add(share.ExpiresAfter.Duration))
+		if expiresAt == nil || after.Before(expiresAt) {
+			expiresAt = &after
+		}
+	}
+	return expiresAt
+}
+
+// recordShareEvent appends an event to the share history, dropping the oldest beyond MaxShareHistory.
+func recordShareEvent(sv *modelv1.SyncedVersion, namespace string, eventType modelv1.ShareEventType, now metav1.Time) {
+	sv.ShareHistory = append(sv.ShareHistory, modelv1.ShareEvent{Namespace: namespace, Type: eventType, Time: now})
+	if extra := len(sv.ShareHistory) - MaxShareHistory; extra > 0 {
+		sv.ShareHistory = append([]modelv1.ShareEvent(nil), sv.ShareHistory[extra:]...)
+	}
+}
+
+// nextShareExpiry returns how long until the next shared namespace expires, or 0 if none will.
+func nextShareExpiry(model *modelv1.Model, now time.Time) time.Duration {
+	var next time.Duration
+	for i := range model.Status.SyncedVersions {
+		for _, target := range model.Status.SyncedVersions[i].ShareTargets {
+			if target.State != modelv1.ShareTargetShared || target.ExpiresAt == nil {
+				continue
+			}
+			wait := target.ExpiresAt.Sub(now)
+			if wait < time.Second {
+				wait = time.Second
+			}
+			if next == 0 || wait < next {
+				next = wait
+			}
+		}
+	}
+	return next
+}
+
+// setSharedCondition summarizes the share targets of all shared versions. The condition
+// is only present while at least one version has sharing enabled.
+func setSharedCondition(status *modelv1.ModelStatus, model *modelv1.Model) {
+	shared := false
+	var targets, failed, waiting, pending, expired []string
+	for _, version := range model.Spec.Versions {
+		if version.Share == nil || !version.Share.Enabled || version.State == modelv1.ModelVersionStateAbsent {
+			continue
+		}
+		shared = true
+		sv := findSyncedVersion(status, version.Name)
+		if sv == nil {
+			continue
+		}
+		for _, target := range sv.ShareTargets {
+			name := fmt.Sprintf("%s -> %s", version.Name, target.Namespace)
+			switch {
+			case target.LastError != "":
+				failed = append(failed, name)
+			case target.State == modelv1.ShareTargetSharePending:
+				waiting = append(waiting, name)
+			case target.State == modelv1.ShareTargetPendingApproval:
+				pending = append(pending, name)
+			case target.State == modelv1.ShareTargetExpired:
+				expired = append(expired, name)
+			default:
+				targets = append(targets, name)
+			}
+		}
+	}
+
+	if !shared {
+		meta.RemoveStatusCondition(&status.Conditions, modelv1.ModelConditionShared)
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               modelv1.ModelConditionShared,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: model.Generation,
+	}
+	switch {
+	case len(failed) > 0:
+		condition.Reason = modelv1.ReasonShareFailed
+		condition.Message = fmt.Sprintf("Sharing failed: %s", strings.Join(failed, ", "))
+	case len(targets) > 0:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = modelv1.ReasonTargetsShared
+		condition.Message = fmt.Sprintf("Shared: %s", strings.Join(targets, ", "))
+		if len(waiting) > 0 {
+			condition.Message += fmt.Sprintf("; waiting for the source: %s", strings.Join(waiting, ", "))
+		}
+		if len(pending) > 0 {
+			condition.Message += fmt.Sprintf("; pending approval: %s", strings.Join(pending, ", "))
+		}
+	case len(waiting) > 0:
+		condition.Reason = modelv1.ReasonSharePending
+		condition.Message = fmt.Sprintf("Waiting for the source: %s", strings.Join(waiting, ", "))
+	case len(pending) > 0:
+		condition.Reason = modelv1.ReasonApprovalPending
+		condition.Message = fmt.Sprintf("Pending approval: %s", strings.Join(pending, ", "))
+	case len(expired) > 0:
+		condition.Reason = modelv1.ReasonShareExpired
+		condition.Message = fmt.Sprintf("Expired: %s", strings.Join(expired, ", "))
+	default:
+		condition.Reason = modelv1.ReasonNoShareTargets
+		condition.Message = "No namespaces match the shares"
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"context"
+
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// patchStatus writes the status computed for model with a single merge patch. Nothing is
+// written if it is semantically equal to the status of original, the Model as read at the
+// start of the reconcile. The patch carries the resourceVersion; on a conflict the Model is
+// read again, with backoff for the cache to catch up, and the computed status is patched
+// onto the latest copy.
+func (r *ModelReconciler) patchStatus(ctx context.Context, original, model *modelv1.Model) error {
+	status := model.Status.DeepCopy()
+	base := original
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if equality.Semantic.DeepEqual(base.Status, *status) {
+			return nil
+		}
+		latest := base.DeepCopy()
+		latest.Status = *status
+		err := r.Status().Patch(ctx, latest, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{}))
+		if err == nil {
+			model.ResourceVersion = latest.ResourceVersion
+			return nil
+		}
+		if !errors.IsConflict(err) {
+			return client.IgnoreNotFound(err)
+		}
+		fresh := &modelv1.Model{}
+		if getErr := r.Get(ctx, types.NamespacedName{Name: model.Name, Namespace: model.Namespace}, fresh); getErr != nil {
+			return client.IgnoreNotFound(getErr)
+		}
+		base = fresh
+		return err
+	})
+}
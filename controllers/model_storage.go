@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	datasetv1alpha1 "github.com/BaizeAI/dataset/api/dataset/v1alpha1"
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	"github.com/samzong/modelfs/pkg/config"
+	"github.com/samzong/modelfs/pkg/upstream"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultStorageHeadroomPercent is added on top of the estimated size when no headroom is configured.
+	DefaultStorageHeadroomPercent = 20
+
+	gibibyte = int64(1) << 30
+)
+
+// sizeVersionStorage sets the PVC size of a Dataset for a version without a storage spec.
+// A Dataset that already exists keeps its PVC size and access modes, since a PVC cannot shrink. A new
+// Dataset gets the default access modes and requests the size of the upstream files the sync
+// downloads plus headroom, or the default storage size when the source cannot list its files
+// or listing them fails.
+func (r *ModelReconciler) sizeVersionStorage(ctx context.Context, source *modelSource, version modelv1.ModelVersion, sv *modelv1.SyncedVersion, spec *datasetv1alpha1.DatasetSpec, existing *datasetv1alpha1.Dataset, defaults config.Defaults) error {
+	if version.Storage != nil {
+		sv.EstimateError = ""
+		return nil
+	}
+	if existing != nil {
+		// Only the fields modelfs sized the PVC with are applied again; the rest of the
+		// template is left to whoever set it
+		template := existing.Spec.VolumeClaimTemplate.Spec
+		spec.VolumeClaimTemplate.Spec.AccessModes = template.AccessModes
+		if storage, ok := template.Resources.Requests[corev1.ResourceStorage]; ok {
+			if spec.VolumeClaimTemplate.Spec.Resources.Requests == nil {
+				spec.VolumeClaimTemplate.Spec.Resources.Requests = corev1.ResourceList{}
+			}
+			spec.VolumeClaimTemplate.Spec.Resources.Requests[corev1.ResourceStorage] = storage
+		}
+		return nil
+	}
+
+	size := defaults.StorageSize
+	estimated, err := r.estimateVersionSize(ctx, source, version, sv)
+	if err != nil {
+		return err
+	}
+	if estimated != nil {
+		size = withHeadroom(estimated.Value(), r.storageHeadroomPercent())
+	}
+	if len(defaults.AccessModes) > 0 {
+		spec.VolumeClaimTemplate.Spec.AccessModes = defaults.AccessModes
+	}
+	if size.IsZero() {
+		return nil
+	}
+	if spec.VolumeClaimTemplate.Spec.Resources.Requests == nil {
+		spec.VolumeClaimTemplate.Spec.Resources.Requests = corev1.ResourceList{}
+	}
+	spec.VolumeClaimTemplate.Spec.Resources.Requests[corev1.ResourceStorage] = size
+	return nil
+}
+
+// estimateVersionSize returns the total size of the files selected by the include and exclude
+// config of the source, recorded in the synced version per "repo@revision". It returns nil
+// if no Sizer is configured, the source type cannot list files, or listing them fails; a
+// failure is recorded in the synced version instead.
+func (r *ModelReconciler) estimateVersionSize(ctx context.Context, source *modelSource, version modelv1.ModelVersion, sv *modelv1.SyncedVersion) (*resource.Quantity, error) {
+	if r.Sizer == nil {
+		return nil, nil
+	}
+	from := fmt.Sprintf("%s@%s", version.Repo, version.Revision)
+	if sv.EstimatedFrom == from && sv.EstimatedSize != nil {
+		return sv.EstimatedSize, nil
+	}
+
+	creds, err := r.sourceCredentials(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	files, err := r.Sizer.ListFiles(ctx, source.Spec, creds, version.Repo, version.Revision)
+	if errors.Is(err, upstream.ErrUnsupported) {
+		sv.EstimatedSize, sv.EstimatedFrom, sv.EstimateError = nil, "", ""
+		return nil, nil
+	}
+	if err != nil {
+		// The PVC gets the default size rather than holding up the Dataset
+		log.FromContext(ctx).Error(err, "Listing upstream files failed, using the default storage size", "version", version.Name)
+		sv.EstimatedSize, sv.EstimatedFrom = nil, ""
+		sv.EstimateError = fmt.Sprintf("list files of %s: %v", from, err)
+		return nil, nil
+	}
+
+	sv.EstimatedSize = resource.NewQuantity(upstream.FilteredSize(source.Spec, files), resource.BinarySI)
+	sv.EstimatedFrom, sv.EstimateError = from, ""
+	return sv.EstimatedSize, nil
+}
+
+// withHeadroom adds percent to size and rounds up to whole GiB.
+func withHeadroom(size int64, percent int) resource.Quantity {
+	total := size + size*int64(percent)/100
+	gib := (total + gibibyte - 1) / gibibyte
+	if gib < 1 {
+		gib = 1
+	}
+	return *resource.NewQuantity(gib*gibibyte, resource.BinarySI)
+}
+
+func (r *ModelReconciler) storageHeadroomPercent() int {
+	if r.StorageHeadroomPercent < 0 {
+		return DefaultStorageHeadroomPercent
+	}
+	return r.StorageHeadroomPercent
+}
@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	datasetv1alpha1 "github.com/BaizeAI/dataset/api/dataset/v1alpha1"
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	"github.com/samzong/modelfs/pkg/dataset"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ModelClaimReconciler fulfills ModelClaims with a REFERENCE Dataset in the claim's namespace.
+// The Dataset is controlled by the claim, so it is garbage collected when the claim is deleted.
+type ModelClaimReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=model.samzong.dev,resources=modelclaims,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=model.samzong.dev,resources=modelclaims/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=model.samzong.dev,resources=models,verbs=get;list;watch
+//+kubebuilder:rbac:groups=dataset.baizeai.io,resources=datasets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop.
+func (r *ModelClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	claim := &modelv1.ModelClaim{}
+	if err := r.Get(ctx, req.NamespacedName, claim); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !claim.DeletionTimestamp.IsZero() {
+		// The Dataset is garbage collected with the claim
+		return ctrl.Result{}, nil
+	}
+
+	original := claim.DeepCopy()
+	err := r.reconcileClaim(ctx, claim)
+	if err != nil {
+		setClaimStatus(claim, modelv1.ModelClaimPhasePending, claim.Status.Dataset, modelv1.ReasonClaimError, err.Error())
+	}
+	claim.Status.ObservedGeneration = claim.Generation
+	if !equality.Semantic.DeepEqual(original.Status, claim.Status) {
+		if patchErr := r.Status().Patch(ctx, claim, client.MergeFrom(original)); patchErr != nil && !errors.IsNotFound(patchErr) {
+			return ctrl.Result{}, patchErr
+		}
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Come back to withdraw the Dataset when the share expires
+	if claim.Status.Phase == modelv1.ModelClaimPhaseBound && claim.Status.ExpiresAt != nil {
+		wait := time.Until(claim.Status.ExpiresAt.Time)
+		if wait < time.Second {
+			wait = time.Second
+		}
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcileClaim binds a claim when the claimed version shares into the claim's namespace, and
+// withdraws its Dataset otherwise. The status is set in memory.
+func (r *ModelClaimReconciler) reconcileClaim(ctx context.Context, claim *modelv1.ModelClaim) error {
+	modelNs, modelName, _ := strings.Cut(claim.Spec.Model, "/")
+	model := &modelv1.Model{}
+	if err := r.Get(ctx, types.NamespacedName{Name: modelName, Namespace: modelNs}, model); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("get model %s: %w", claim.Spec.Model, err)
+		}
+		return r.deny(ctx, claim, modelv1.ReasonModelNotFound, fmt.Sprintf("Model %s not found", claim.Spec.Model))
+	}
+	if !model.DeletionTimestamp.IsZero() {
+		return r.deny(ctx, claim, modelv1.ReasonModelNotFound, fmt.Sprintf("Model %s is being deleted", claim.Spec.Model))
+	}
+	if model.Namespace == claim.Namespace {
+		return r.deny(ctx, claim, modelv1.ReasonShareNotAllowed, "Model is in the claim's namespace; use its Dataset directly")
+	}
+
+	var version *modelv1.ModelVersion
+	for i := range model.Spec.Versions {
+		if model.Spec.Versions[i].Name == claim.Spec.Version {
+			version = &model.Spec.Versions[i]
+			break
+		}
+	}
+	if version == nil || version.State == modelv1.ModelVersionStateAbsent {
+		return r.deny(ctx, claim, modelv1.ReasonVersionNotFound, fmt.Sprintf("Version %q of Model %s not found", claim.Spec.Version, claim.Spec.Model))
+	}
+
+	// The claim is the consumer's opt-in, so only the namespace selector applies
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: claim.Namespace}, ns); err != nil {
+		return fmt.Errorf("get namespace %s: %w", claim.Namespace, err)
+	}
+	allowed := version.Share != nil && version.Share.Enabled
+	if allowed {
+		ok, err := shareSelectsNamespace(version.Share, ns)
+		if err != nil {
+			return fmt.Errorf("match namespace selector: %w", err)
+		}
+		allowed = ok
+	}
+	if !allowed {
+		return r.deny(ctx, claim, modelv1.ReasonShareNotAllowed, fmt.Sprintf("Version %q of Model %s is not shared with namespace %s", version.Name, claim.Spec.Model, claim.Namespace))
+	}
+
+	if version.Share.Mode == modelv1.ShareModeCopy {
+		return r.deny(ctx, claim, modelv1.ReasonShareNotAllowed, fmt.Sprintf("Version %q of Model %s is shared in Copy mode, which claims do not support", version.Name, claim.Spec.Model))
+	}
+
+	// Approval and expiry apply as for pushed shares, counted from when the claim was granted
+	now := metav1.Now()
+	if !shareApproved(model, version.Name, version.Share, claim.Namespace) {
+		claim.Status.GrantedAt = nil
+		claim.Status.ExpiresAt = nil
+		if err := r.withdraw(ctx, claim); err != nil {
+			return err
+		}
+		setClaimStatus(claim, modelv1.ModelClaimPhasePending, "", modelv1.ReasonApprovalPending,
+			fmt.Sprintf("Waiting for the owner of Model %s to approve namespace %s", claim.Spec.Model, claim.Namespace))
+		return nil
+	}
+	if claim.Status.GrantedAt == nil {
+		claim.Status.GrantedAt = &now
+	}
+	claim.Status.ExpiresAt = shareExpiry(version.Share, *claim.Status.GrantedAt)
+	if claim.Status.ExpiresAt != nil && !now.Before(claim.Status.ExpiresAt) {
+		return r.deny(ctx, claim, modelv1.ReasonShareExpired, fmt.Sprintf("Share expired at %s", claim.Status.ExpiresAt.UTC().Format(time.RFC3339)))
+	}
+
+	sv := findSyncedVersion(&model.Status, version.Name)
+	if sv == nil || sv.ActiveDataset == "" {
+		setClaimStatus(claim, modelv1.ModelClaimPhasePending, claim.Status.Dataset, modelv1.ReasonVersionNotSynced, "Waiting for the version to be synced")
+		return nil
+	}
+
+	existing := &datasetv1alpha1.Dataset{}
+	err := r.Get(ctx, types.NamespacedName{Name: claim.Name, Namespace: claim.Namespace}, existing)
+	switch {
+	case err == nil:
+		if !metav1.IsControlledBy(existing, claim) {
+			setClaimStatus(claim, modelv1.ModelClaimPhasePending, "", modelv1.ReasonDatasetConflict, fmt.Sprintf("Dataset %s already exists and is not owned by the claim", claim.Name))
+			return nil
+		}
+	case !errors.IsNotFound(err):
+		return fmt.Errorf("get dataset %s: %w", claim.Name, err)
+	}
+
+	// Wait for the source to be Ready, and replace a reference to a replaced source Dataset
+	sourceReady, err := datasetReady(ctx, r.Client, model.Namespace, sv.ActiveDataset)
+	if err != nil {
+		return fmt.Errorf("get source dataset: %w", err)
+	}
+	pending, err := referenceWaits(ctx, r.Client, claim.Namespace, claim.Name, dataset.ReferenceURI(model.Namespace, sv.ActiveDataset), sourceReady,
+		func(ds *datasetv1alpha1.Dataset) bool { return metav1.IsControlledBy(ds, claim) })
+	if err != nil {
+		return fmt.Errorf("check reference dataset: %w", err)
+	}
+	if pending {
+		setClaimStatus(claim, modelv1.ModelClaimPhasePending, "", modelv1.ReasonSharePending,
+			fmt.Sprintf("Waiting for the source Dataset %s/%s", model.Namespace, sv.ActiveDataset))
+		return nil
+	}
+
+	ownerRef := metav1.NewControllerRef(claim, modelv1.GroupVersion.WithKind("ModelClaim"))
+	if err := dataset.EnsureReferenceDataset(ctx, r.Client, model.Namespace, sv.ActiveDataset, claim.Namespace, claim.Name,
+		dataset.Labels(model.Namespace, model.Name, version.Name), dataset.Annotations(model.Namespace, model.Name, version.Name), ownerRef); err != nil {
+		return fmt.Errorf("ensure reference dataset: %w", err)
+	}
+	setClaimStatus(claim, modelv1.ModelClaimPhaseBound, claim.Name, modelv1.ReasonClaimBound,
+		fmt.Sprintf("Dataset %s references %s/%s", claim.Name, model.Namespace, sv.ActiveDataset))
+	return nil
+}
+
+// deny marks a claim Denied and deletes the Dataset it was bound to.
+func (r *ModelClaimReconciler) deny(ctx context.Context, claim *modelv1.ModelClaim, reason, message string) error {
+	if err := r.withdraw(ctx, claim); err != nil {
+		return err
+	}
+	setClaimStatus(claim, modelv1.ModelClaimPhaseDenied, "", reason, message)
+	return nil
+}
+
+// withdraw deletes the Dataset a claim was bound to.
+func (r *ModelClaimReconciler) withdraw(ctx context.Context, claim *modelv1.ModelClaim) error {
+	ds := &datasetv1alpha1.Dataset{}
+	if err := r.Get(ctx, types.NamespacedName{Name: claim.Name, Namespace: claim.Namespace}, ds); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("get dataset %s: %w", claim.Name, err)
+		}
+	} else if metav1.IsControlledBy(ds, claim) {
+		if err := r.Delete(ctx, ds); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("delete dataset %s: %w", claim.Name, err)
+		}
+	}
+	return nil
+}
+
+func setClaimStatus(claim *modelv1.ModelClaim, phase modelv1.ModelClaimPhase, datasetName, reason, message string) {
+	claim.Status.Phase = phase
+	claim.Status.Dataset = datasetName
+	status := metav1.ConditionFalse
+	if phase == modelv1.ModelClaimPhaseBound {
+		status = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&claim.Status.Conditions, metav1.Condition{
+		Type:               modelv1.ClaimConditionBound,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: claim.Generation,
+	})
+}
+
+// isClaimDataset reports whether a Dataset is controlled by a ModelClaim.
+func isClaimDataset(obj metav1.Object) bool {
+	owner := metav1.GetControllerOf(obj)
+	return owner != nil && owner.Kind == "ModelClaim" && owner.APIVersion == modelv1.GroupVersion.String()
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ModelClaimReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&modelv1.ModelClaim{}).
+		Owns(&datasetv1alpha1.Dataset{}).
+		Watches(
+			&modelv1.Model{},
+			handler.EnqueueRequestsFromMapFunc(r.mapModelToClaims),
+		).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToClaims),
+		).
+		Complete(r)
+}
+
+func (r *ModelClaimReconciler) mapModelToClaims(ctx context.Context, obj client.Object) []reconcile.Request {
+	claimList := &modelv1.ModelClaimList{}
+	if err := r.List(ctx, claimList, client.MatchingFields{ModelClaimModelIndex: formatNamespacedName(obj.GetNamespace(), obj.GetName())}); err != nil {
+		return []reconcile.Request{}
+	}
+	return claimRequests(claimList.Items)
+}
+
+func (r *ModelClaimReconciler) mapNamespaceToClaims(ctx context.Context, obj client.Object) []reconcile.Request {
+	claimList := &modelv1.ModelClaimList{}
+	if err := r.List(ctx, claimList, client.InNamespace(obj.GetName())); err != nil {
+		return []reconcile.Request{}
+	}
+	return claimRequests(claimList.Items)
+}
+
+func claimRequests(claims []modelv1.ModelClaim) []reconcile.Request {
+	requests := make([]reconcile.Request, 0, len(claims))
+	for _, claim := range claims {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: claim.Name, Namespace: claim.Namespace},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	"github.com/samzong/modelfs/pkg/config"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// ModelfsConfigReconciler loads the ModelfsConfig named "default" into a config.Store.
+// It runs on every replica, not only the leader, so that webhooks served by any
+// replica see the same defaults as the controllers.
+type ModelfsConfigReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Store  *config.Store
+}
+
+//+kubebuilder:rbac:groups=model.samzong.dev,resources=modelfsconfigs,verbs=get;list;watch
+//+kubebuilder:rbac:groups=model.samzong.dev,resources=modelfsconfigs/status,verbs=get;update;patch
+
+// Reconcile is part of the main kubernetes reconciliation loop.
+func (r *ModelfsConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	cfg := &modelv1.ModelfsConfig{}
+	if err := r.Get(ctx, types.NamespacedName{Name: modelv1.ModelfsConfigName}, cfg); err != nil {
+		if errors.IsNotFound(err) {
+			// Fall back to the operator defaults
+			r.Store.Set(nil)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !cfg.DeletionTimestamp.IsZero() {
+		r.Store.Set(nil)
+		return ctrl.Result{}, nil
+	}
+
+	r.Store.Set(&cfg.Spec)
+
+	if cfg.Status.ObservedGeneration == cfg.Generation {
+		return ctrl.Result{}, nil
+	}
+	cfg.Status.ObservedGeneration = cfg.Generation
+	if err := r.Status().Update(ctx, cfg); err != nil && !errors.IsConflict(err) {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ModelfsConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	needLeaderElection := false
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&modelv1.ModelfsConfig{}, builder.WithPredicates(
+			predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetName() == modelv1.ModelfsConfigName
+			}),
+			predicate.GenerationChangedPredicate{},
+		)).
+		WithOptions(controller.Options{NeedLeaderElection: &needLeaderElection}).
+		Complete(r)
+}
@@ -3,14 +3,19 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	modelv1 "github.com/samzong/modelfs/api/v1"
+	"github.com/samzong/modelfs/pkg/upstream"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
@@ -21,6 +26,10 @@ const (
 type ModelSourceReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Probers check the source endpoint and credentials per source type. Sources are not probed if nil.
+	Probers upstream.Probers
+	// ProbeInterval is how often a source is probed. Defaults to DefaultSourceProbeInterval.
+	ProbeInterval time.Duration
 }
 
 //+kubebuilder:rbac:groups=model.samzong.dev,resources=modelsources,verbs=get;list;watch;create;update;patch;delete
@@ -47,30 +56,42 @@ func (r *ModelSourceReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	// Validate Secret (optional for some source types)
+	previousHash := source.Status.SecretHash
+	var creds upstream.Credentials
 	if source.Spec.SecretRef != "" {
-		if err := r.validateSecret(ctx, source); err != nil {
+		mapped, err := r.validateSecret(ctx, source)
+		if err != nil {
+			source.Status.SecretHash = ""
 			if updateErr := r.updateCredentialsCondition(ctx, source, false, "SecretInvalid", err.Error()); updateErr != nil {
 				return ctrl.Result{}, updateErr
 			}
 			return ctrl.Result{}, err
 		}
+		// Record the hash of the credentials so dependent Models notice a rotation
+		source.Status.SecretHash = secretDataHash(mapped)
+		creds = upstream.CredentialsFromSecretData(mapped)
 		// Update CredentialsReady condition
 		if err := r.updateCredentialsCondition(ctx, source, true, "SecretValid", "Secret is valid and accessible"); err != nil {
 			return ctrl.Result{}, err
 		}
 	} else {
 		// No secret required (e.g., public HuggingFace models)
+		source.Status.SecretHash = ""
 		if err := r.updateCredentialsCondition(ctx, source, true, "NoSecretRequired", "No secret required for this source type"); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
+	// Probe the endpoint with the credentials; written together with referencedBy
+	nextProbe := probeSourceIfDue(ctx, r.Probers, r.ProbeInterval, source.Spec, creds, &source.Status,
+		source.Generation, previousHash != source.Status.SecretHash)
+
 	// Find and update referenced Models
 	if err := r.updateReferencedBy(ctx, source); err != nil {
 		return ctrl.Result{}, fmt.Errorf("update referencedBy: %w", err)
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: nextProbe}, nil
 }
 
 func (r *ModelSourceReconciler) handleDeletion(ctx context.Context, source *modelv1.ModelSource) (ctrl.Result, error) {
@@ -115,17 +136,8 @@ func (r *ModelSourceReconciler) ensureFinalizer(ctx context.Context, source *mod
 	return nil
 }
 
-func (r *ModelSourceReconciler) validateSecret(ctx context.Context, source *modelv1.ModelSource) error {
-	secret := &corev1.Secret{}
-	key := types.NamespacedName{Namespace: source.Namespace, Name: source.Spec.SecretRef}
-	if err := r.Get(ctx, key, secret); err != nil {
-		return fmt.Errorf("get secret %s: %w", source.Spec.SecretRef, err)
-	}
-	// Basic validation: secret exists and is not empty
-	if len(secret.Data) == 0 && len(secret.StringData) == 0 {
-		return fmt.Errorf("secret %s is empty", source.Spec.SecretRef)
-	}
-	return nil
+func (r *ModelSourceReconciler) validateSecret(ctx context.Context, source *modelv1.ModelSource) (map[string][]byte, error) {
+	return validateSourceSecret(ctx, r.Client, source.Namespace, source.Spec)
 }
 
 func (r *ModelSourceReconciler) updateCredentialsCondition(ctx context.Context, source *modelv1.ModelSource, ready bool, reason, message string) error {
@@ -167,17 +179,11 @@ func (r *ModelSourceReconciler) updateCredentialsCondition(ctx context.Context,
 
 func (r *ModelSourceReconciler) findReferencingModels(ctx context.Context, source *modelv1.ModelSource) ([]modelv1.Model, error) {
 	modelList := &modelv1.ModelList{}
-	if err := r.List(ctx, modelList, client.InNamespace(source.Namespace)); err != nil {
+	if err := r.List(ctx, modelList, client.InNamespace(source.Namespace),
+		client.MatchingFields{ModelSourceRefIndex: sourceRefIndexValue(modelv1.SourceKindModelSource, source.Name)}); err != nil {
 		return nil, err
 	}
-
-	var referencing []modelv1.Model
-	for _, model := range modelList.Items {
-		if model.Spec.SourceRef == source.Name {
-			referencing = append(referencing, model)
-		}
-	}
-	return referencing, nil
+	return modelList.Items, nil
 }
 
 func (r *ModelSourceReconciler) updateReferencedBy(ctx context.Context, source *modelv1.ModelSource) error {
@@ -199,15 +205,38 @@ func (r *ModelSourceReconciler) updateReferencedBy(ctx context.Context, source *
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ModelSourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	// Index Models by sourceRef for efficient lookup
-	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &modelv1.Model{}, "spec.sourceRef", func(obj client.Object) []string {
-		model := obj.(*modelv1.Model)
-		return []string{model.Spec.SourceRef}
-	}); err != nil {
-		return fmt.Errorf("index field spec.sourceRef: %w", err)
-	}
-
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&modelv1.ModelSource{}).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.mapSecretToModelSource),
+			builder.WithPredicates(sourceSecretPredicate()),
+		).
 		Complete(r)
 }
+
+func (r *ModelSourceReconciler) mapSecretToModelSource(ctx context.Context, obj client.Object) []reconcile.Request {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return []reconcile.Request{}
+	}
+
+	// Find all ModelSources in the Secret's namespace that reference it
+	sourceList := &modelv1.ModelSourceList{}
+	if err := r.List(ctx, sourceList, client.InNamespace(secret.Namespace),
+		client.MatchingFields{SourceSecretRefIndex: secret.Name}); err != nil {
+		return []reconcile.Request{}
+	}
+
+	requests := make([]reconcile.Request, 0, len(sourceList.Items))
+	for _, source := range sourceList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      source.Name,
+				Namespace: source.Namespace,
+			},
+		})
+	}
+
+	return requests
+}
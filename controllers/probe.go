@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	"github.com/samzong/modelfs/pkg/upstream"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultSourceProbeInterval is how often source endpoints are probed when no interval is configured.
+	DefaultSourceProbeInterval = 5 * time.Minute
+)
+
+// probeSourceIfDue probes the endpoint of a source when the last probe is older than interval,
+// or when the spec or credentials changed since, and records the Reachable and Authenticated
+// conditions. It returns how long until the next probe is due, or 0 if the type has no prober.
+func probeSourceIfDue(ctx context.Context, probers upstream.Probers, interval time.Duration, spec modelv1.ModelSourceSpec, creds upstream.Credentials, status *modelv1.ModelSourceStatus, generation int64, secretChanged bool) time.Duration {
+	prober, ok := probers[spec.Type]
+	if !ok {
+		return 0
+	}
+	if interval <= 0 {
+		interval = DefaultSourceProbeInterval
+	}
+
+	now := time.Now()
+	reachable := meta.FindStatusCondition(status.Conditions, modelv1.SourceConditionReachable)
+	if !secretChanged && reachable != nil && reachable.ObservedGeneration == generation && status.LastProbeTime != nil {
+		if wait := status.LastProbeTime.Add(interval).Sub(now); wait > 0 {
+			return wait
+		}
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, interval)
+	defer cancel()
+	err := prober.Probe(probeCtx, spec, creds)
+	latency := time.Since(now)
+
+	status.LastProbeTime = &metav1.Time{Time: now}
+	status.ProbeLatency = &metav1.Duration{Duration: latency.Round(time.Millisecond)}
+	setProbeConditions(status, generation, err, creds != upstream.Credentials{}, latency)
+	return interval
+}
+
+// setProbeConditions maps a probe result to the Reachable and Authenticated conditions.
+func setProbeConditions(status *modelv1.ModelSourceStatus, generation int64, err error, hasCredentials bool, latency time.Duration) {
+	reachable := metav1.Condition{Type: modelv1.SourceConditionReachable, ObservedGeneration: generation}
+	authenticated := metav1.Condition{Type: modelv1.SourceConditionAuthenticated, ObservedGeneration: generation}
+	took := latency.Round(time.Millisecond)
+
+	switch {
+	case err == nil:
+		reachable.Status, reachable.Reason = metav1.ConditionTrue, modelv1.ReasonProbeSucceeded
+		reachable.Message = fmt.Sprintf("Endpoint answered in %s", took)
+		if hasCredentials {
+			authenticated.Status, authenticated.Reason = metav1.ConditionTrue, modelv1.ReasonProbeSucceeded
+			authenticated.Message = "Credentials accepted by the endpoint"
+		} else {
+			authenticated.Status, authenticated.Reason = metav1.ConditionUnknown, modelv1.ReasonAnonymous
+			authenticated.Message = "No credentials configured"
+		}
+	case errors.Is(err, upstream.ErrNoProbeTarget):
+		reachable.Status, reachable.Reason = metav1.ConditionUnknown, modelv1.ReasonNoProbeTarget
+		reachable.Message = err.Error()
+		authenticated.Status, authenticated.Reason = metav1.ConditionUnknown, modelv1.ReasonNoProbeTarget
+		authenticated.Message = err.Error()
+	case errors.Is(err, upstream.ErrUnauthorized):
+		reachable.Status, reachable.Reason = metav1.ConditionTrue, modelv1.ReasonProbeSucceeded
+		reachable.Message = fmt.Sprintf("Endpoint answered in %s", took)
+		authenticated.Status, authenticated.Reason = metav1.ConditionFalse, modelv1.ReasonCredentialsRejected
+		authenticated.Message = err.Error()
+	case upstream.IsUnreachable(err):
+		reachable.Status, reachable.Reason = metav1.ConditionFalse, modelv1.ReasonEndpointUnreachable
+		reachable.Message = fmt.Sprintf("%v (after %s)", err, took)
+		authenticated.Status, authenticated.Reason = metav1.ConditionUnknown, modelv1.ReasonEndpointUnreachable
+		authenticated.Message = "Endpoint not reachable"
+	default:
+		// The endpoint answered, but not in a way that tells whether the credentials are valid
+		reachable.Status, reachable.Reason = metav1.ConditionTrue, modelv1.ReasonProbeSucceeded
+		reachable.Message = fmt.Sprintf("Endpoint answered in %s", took)
+		authenticated.Status, authenticated.Reason = metav1.ConditionUnknown, modelv1.ReasonProbeFailed
+		authenticated.Message = err.Error()
+	}
+
+	meta.SetStatusCondition(&status.Conditions, reachable)
+	meta.SetStatusCondition(&status.Conditions, authenticated)
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	"github.com/samzong/modelfs/pkg/dataset"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// DefaultOperatorNamespace holds the Secrets of ClusterModelSources when no namespace is configured.
+	DefaultOperatorNamespace = "modelfs-system"
+)
+
+// modelSource is the ModelSource or ClusterModelSource a Model's sourceRef resolves to.
+type modelSource struct {
+	Ref    modelv1.SourceReference
+	Spec   modelv1.ModelSourceSpec
+	Status modelv1.ModelSourceStatus
+	// SecretNamespace is the namespace of the Secret named by Spec.SecretRef.
+	SecretNamespace string
+}
+
+// getModelSource resolves the sourceRef of a Model. A ModelSource is looked up in the
+// Model's namespace; a ClusterModelSource keeps its Secret in the operator namespace.
+func getModelSource(ctx context.Context, c client.Client, model *modelv1.Model, operatorNamespace string) (*modelSource, error) {
+	ref := model.Spec.SourceRef
+	if ref.IsCluster() {
+		cms := &modelv1.ClusterModelSource{}
+		if err := c.Get(ctx, types.NamespacedName{Name: ref.Name}, cms); err != nil {
+			return nil, fmt.Errorf("get clustermodelsource %s: %w", ref.Name, err)
+		}
+		return &modelSource{Ref: ref, Spec: cms.Spec, Status: cms.Status, SecretNamespace: operatorNamespace}, nil
+	}
+
+	ms := &modelv1.ModelSource{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: model.Namespace, Name: ref.Name}, ms); err != nil {
+		return nil, fmt.Errorf("get modelsource %s: %w", ref.Name, err)
+	}
+	return &modelSource{Ref: ref, Spec: ms.Spec, Status: ms.Status, SecretNamespace: model.Namespace}, nil
+}
+
+// validateSourceSecret checks that the credentials Secret of a source exists,
+// contains the keys named in secretKeys, and provides the credentials its type requires.
+// It returns the mapped credentials.
+func validateSourceSecret(ctx context.Context, c client.Client, namespace string, spec modelv1.ModelSourceSpec) (map[string][]byte, error) {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: namespace, Name: spec.SecretRef}
+	if err := c.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("get secret %s: %w", spec.SecretRef, err)
+	}
+	// Basic validation: secret exists and is not empty
+	if len(secret.Data) == 0 && len(secret.StringData) == 0 {
+		return nil, fmt.Errorf("secret %s is empty", spec.SecretRef)
+	}
+
+	mapped, err := dataset.MapSecretData(spec, secret.Data)
+	if err != nil {
+		return nil, err
+	}
+	if err := dataset.ValidateCredentials(spec.Type, mapped); err != nil {
+		return nil, fmt.Errorf("secret %s: %w", spec.SecretRef, err)
+	}
+	return mapped, nil
+}
+
+// helmReleaseSecretType is the type of the Secrets Helm stores release state in.
+const helmReleaseSecretType corev1.SecretType = "helm.sh/release.v1"
+
+// sourceSecretPredicate filters the Secret watches of the source reconcilers down to
+// Secrets that can hold source credentials, and to updates that change their data.
+func sourceSecretPredicate() predicate.Predicate {
+	candidate := func(obj client.Object) bool {
+		secret, ok := obj.(*corev1.Secret)
+		return ok && secret.Type != corev1.SecretTypeServiceAccountToken && secret.Type != helmReleaseSecretType
+	}
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return candidate(e.Object) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return candidate(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return candidate(e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if !candidate(e.ObjectNew) {
+				return false
+			}
+			oldSecret, ok := e.ObjectOld.(*corev1.Secret)
+			return !ok || !equality.Semantic.DeepEqual(oldSecret.Data, e.ObjectNew.(*corev1.Secret).Data)
+		},
+	}
+}
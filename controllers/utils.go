@@ -1,6 +1,14 @@
 package controllers
 
-import "fmt"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	modelv1 "github.com/samzong/modelfs/api/v1"
+)
 
 // containsString checks if a string slice contains a specific string.
 func containsString(slice []string, s string) bool {
@@ -28,10 +36,94 @@ func formatNamespacedName(namespace, name string) string {
 	return fmt.Sprintf("%s/%s", namespace, name)
 }
 
-// buildModelLabels builds a label map for Model resources.
-func buildModelLabels(namespace, modelName, versionName string) map[string]string {
-	return map[string]string{
-		"modelfs.samzong.dev/model":   formatNamespacedName(namespace, modelName),
-		"modelfs.samzong.dev/version": versionName,
+// findSyncedVersion returns the status entry for a version, or nil if there is none.
+func findSyncedVersion(status *modelv1.ModelStatus, versionName string) *modelv1.SyncedVersion {
+	for i := range status.SyncedVersions {
+		if status.SyncedVersions[i].Name == versionName {
+			return &status.SyncedVersions[i]
+		}
+	}
+	return nil
+}
+
+// ensureSyncedVersion returns the status entry for a version, adding one if needed.
+func ensureSyncedVersion(status *modelv1.ModelStatus, versionName string) *modelv1.SyncedVersion {
+	if sv := findSyncedVersion(status, versionName); sv != nil {
+		return sv
+	}
+	status.SyncedVersions = append(status.SyncedVersions, modelv1.SyncedVersion{Name: versionName})
+	return &status.SyncedVersions[len(status.SyncedVersions)-1]
+}
+
+// carryOverSyncedVersion copies the fields recorded by the controller, rather than
+// read from the Dataset, from the previous status entry of a version.
+func carryOverSyncedVersion(sv, previous *modelv1.SyncedVersion) {
+	sv.PendingDataset = previous.PendingDataset
+	sv.RetiringDatasets = previous.RetiringDatasets
+	sv.ObservedVersionHash = previous.ObservedVersionHash
+	sv.ResolvedRevision = previous.ResolvedRevision
+	sv.ResolvedFrom = previous.ResolvedFrom
+	sv.LatestRevision = previous.LatestRevision
+	sv.LastUpdateCheck = previous.LastUpdateCheck
+	sv.ResolveError = previous.ResolveError
+	sv.Preflight = previous.Preflight
+	sv.EstimatedSize = previous.EstimatedSize
+	sv.EstimatedFrom = previous.EstimatedFrom
+	sv.EstimateError = previous.EstimateError
+	sv.LastResyncRequest = previous.LastResyncRequest
+	sv.LastResyncHandled = previous.LastResyncHandled
+	sv.ShareTargets = previous.ShareTargets
+	sv.ShareHistory = previous.ShareHistory
+	sv.ShareSnapshot = previous.ShareSnapshot
+	sv.RetainedVolume = previous.RetainedVolume
+	sv.OrphanedDataset = previous.OrphanedDataset
+}
+
+// shortSHA abbreviates a commit SHA for messages.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// minRequeue returns the shorter of two non-zero requeue delays.
+func minRequeue(a, b time.Duration) time.Duration {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// versionDatasetNames returns the names of all Datasets recorded for a version.
+func versionDatasetNames(sv *modelv1.SyncedVersion) []string {
+	var names []string
+	if sv.ActiveDataset != "" {
+		names = append(names, sv.ActiveDataset)
+	}
+	if sv.PendingDataset != "" {
+		names = append(names, sv.PendingDataset)
+	}
+	for _, retiring := range sv.RetiringDatasets {
+		names = append(names, retiring.Name)
+	}
+	return names
+}
+
+// secretDataHash returns a short, stable hash of Secret data.
+func secretDataHash(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write(data[key])
+		h.Write([]byte{0})
 	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
 }
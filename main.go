@@ -1,13 +1,16 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -15,10 +18,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	datasetv1alpha1 "github.com/BaizeAI/dataset/api/dataset/v1alpha1"
 	modelv1 "github.com/samzong/modelfs/api/v1"
 	"github.com/samzong/modelfs/controllers"
+	"github.com/samzong/modelfs/pkg/config"
+	"github.com/samzong/modelfs/pkg/upstream"
+	"github.com/samzong/modelfs/webhooks"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -40,11 +47,39 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var enableWebhooks bool
+	var webhookPort int
+	var webhookCertDir string
+	var retiredDatasetGracePeriod time.Duration
+	var operatorNamespace string
+	var sourceProbeInterval time.Duration
+	var preflightVersions bool
+	var storageHeadroomPercent int
+	var defaultStorageSize string
+	var deletionCleanupTimeout time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Enable the Model and ModelSource admission webhooks. Requires a serving certificate in --webhook-cert-dir.")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the webhook server binds to.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "", "The directory containing tls.crt and tls.key for the webhook server.")
+	flag.DurationVar(&retiredDatasetGracePeriod, "retired-dataset-grace-period", controllers.DefaultRetiredDatasetGracePeriod,
+		"How long a Dataset replaced by a new version spec is kept before it is deleted.")
+	flag.StringVar(&operatorNamespace, "operator-namespace", envOrDefault("POD_NAMESPACE", controllers.DefaultOperatorNamespace),
+		"The namespace holding the Secrets referenced by ClusterModelSources.")
+	flag.DurationVar(&sourceProbeInterval, "source-probe-interval", controllers.DefaultSourceProbeInterval,
+		"How often ModelSource and ClusterModelSource endpoints are probed for reachability and credentials.")
+	flag.BoolVar(&preflightVersions, "preflight-versions", false,
+		"Check that a version's repo and revision exist and are accessible before creating its Dataset.")
+	flag.IntVar(&storageHeadroomPercent, "storage-headroom-percent", controllers.DefaultStorageHeadroomPercent,
+		"Percentage added to the estimated upstream size when sizing the PVC of a version without a storage spec.")
+	flag.DurationVar(&deletionCleanupTimeout, "deletion-cleanup-timeout", controllers.DefaultDeletionCleanupTimeout,
+		"How long a deleted Model waits for its Datasets and shares to be deleted before the force-release annotation is honored.")
+	flag.StringVar(&defaultStorageSize, "default-storage-size", config.DefaultStorageSize,
+		"PVC size requested for a version without a storage spec when the upstream size cannot be estimated. A ModelfsConfig overrides it.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -59,28 +94,103 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "modelfs.samzong.dev",
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port:    webhookPort,
+			CertDir: webhookCertDir,
+		}),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	// Defaults come from flags and built-in values until a ModelfsConfig overrides them
+	baseDefaults := config.Builtin()
+	if baseDefaults.StorageSize, err = resource.ParseQuantity(defaultStorageSize); err != nil {
+		setupLog.Error(err, "invalid --default-storage-size")
+		os.Exit(1)
+	}
+	store := config.NewStore(baseDefaults)
+
 	// Setup controllers
-	if err = (&controllers.ModelReconciler{
+	if err = controllers.SetupIndexes(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		setupLog.Error(err, "unable to set up cache indexes")
+		os.Exit(1)
+	}
+	if err = (&controllers.ModelfsConfigReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+		Store:  store,
 	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ModelfsConfig")
+		os.Exit(1)
+	}
+
+	resolver := upstream.NewHTTPResolver(nil)
+	modelReconciler := &controllers.ModelReconciler{
+		Client:                    mgr.GetClient(),
+		Scheme:                    mgr.GetScheme(),
+		Resolver:                  resolver,
+		RetiredDatasetGracePeriod: retiredDatasetGracePeriod,
+		OperatorNamespace:         operatorNamespace,
+		Sizer:                     resolver,
+		StorageHeadroomPercent:    storageHeadroomPercent,
+		Config:                    store,
+		DeletionCleanupTimeout:    deletionCleanupTimeout,
+		APIReader:                 mgr.GetAPIReader(),
+	}
+	if preflightVersions {
+		modelReconciler.Preflighter = resolver
+	}
+	if err = modelReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Model")
 		os.Exit(1)
 	}
 
-	if err = (&controllers.ModelSourceReconciler{
+	if err = (&controllers.ModelClaimReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ModelClaim")
+		os.Exit(1)
+	}
+
+	probers := upstream.NewHTTPProbers(nil)
+	if err = (&controllers.ModelSourceReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Probers:       probers,
+		ProbeInterval: sourceProbeInterval,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ModelSource")
 		os.Exit(1)
 	}
+
+	if err = (&controllers.ClusterModelSourceReconciler{
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		OperatorNamespace: operatorNamespace,
+		Probers:           probers,
+		ProbeInterval:     sourceProbeInterval,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterModelSource")
+		os.Exit(1)
+	}
+
+	if enableWebhooks {
+		if err = webhooks.SetupModelWebhookWithManager(mgr, store); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Model")
+			os.Exit(1)
+		}
+		if err = webhooks.SetupModelSourceWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ModelSource")
+			os.Exit(1)
+		}
+		if err = webhooks.SetupClusterModelSourceWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ClusterModelSource")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -98,3 +208,11 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// envOrDefault returns the value of an environment variable, or def if it is unset.
+func envOrDefault(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"sync"
+	"time"
+
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// DefaultStorageSize is the built-in PVC size of a version without storage.
+	DefaultStorageSize = "100Ti"
+	// DefaultRevision is the built-in revision of a version that does not set one.
+	DefaultRevision = "main"
+	// DefaultErrorRequeueInterval is the built-in wait before a Model is retried after a reconcile error.
+	DefaultErrorRequeueInterval = time.Minute
+	// DefaultDatasetNamePrefix is the built-in prefix of version Dataset names.
+	DefaultDatasetNamePrefix = "mdl-"
+	// DefaultShareNamePrefix is the built-in prefix of shared REFERENCE Dataset names.
+	DefaultShareNamePrefix = "share-"
+)
+
+// Defaults are the effective settings for a namespace.
+type Defaults struct {
+	StorageSize          resource.Quantity
+	AccessModes          []corev1.PersistentVolumeAccessMode
+	Revision             string
+	ErrorRequeueInterval time.Duration
+	DatasetNamePrefix    string
+	ShareNamePrefix      string
+}
+
+// Builtin returns the defaults used when no ModelfsConfig sets them.
+func Builtin() Defaults {
+	return Defaults{
+		StorageSize:          resource.MustParse(DefaultStorageSize),
+		AccessModes:          []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+		Revision:             DefaultRevision,
+		ErrorRequeueInterval: DefaultErrorRequeueInterval,
+		DatasetNamePrefix:    DefaultDatasetNamePrefix,
+		ShareNamePrefix:      DefaultShareNamePrefix,
+	}
+}
+
+// Store holds the ModelfsConfig currently in effect. It is updated by the ModelfsConfig
+// controller and read by the other controllers and webhooks, so changes apply without a restart.
+// A nil Store returns the built-in defaults.
+type Store struct {
+	mu   sync.RWMutex
+	base Defaults
+	spec *modelv1.ModelfsConfigSpec
+}
+
+// NewStore returns a Store that falls back to base where the ModelfsConfig sets nothing.
+func NewStore(base Defaults) *Store {
+	return &Store{base: base}
+}
+
+// Set replaces the ModelfsConfig in effect; nil removes it.
+func (s *Store) Set(spec *modelv1.ModelfsConfigSpec) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if spec != nil {
+		spec = spec.DeepCopy()
+	}
+	s.spec = spec
+}
+
+// For returns the defaults for namespace: its override, then the global defaults, then the base.
+func (s *Store) For(namespace string) Defaults {
+	if s == nil {
+		return Builtin()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	defaults := s.base
+	defaults.AccessModes = append([]corev1.PersistentVolumeAccessMode(nil), s.base.AccessModes...)
+	if s.spec == nil {
+		return defaults
+	}
+	merge(&defaults, s.spec.Defaults)
+	for _, ns := range s.spec.Namespaces {
+		if ns.Namespace == namespace {
+			merge(&defaults, ns.ModelfsDefaults)
+			break
+		}
+	}
+	return defaults
+}
+
+func merge(defaults *Defaults, override modelv1.ModelfsDefaults) {
+	if override.StorageSize != nil {
+		defaults.StorageSize = override.StorageSize.DeepCopy()
+	}
+	if len(override.AccessModes) > 0 {
+		defaults.AccessModes = append([]corev1.PersistentVolumeAccessMode(nil), override.AccessModes...)
+	}
+	if override.Revision != "" {
+		defaults.Revision = override.Revision
+	}
+	if override.ErrorRequeueInterval != nil && override.ErrorRequeueInterval.Duration > 0 {
+		defaults.ErrorRequeueInterval = override.ErrorRequeueInterval.Duration
+	}
+	if override.DatasetNamePrefix != "" {
+		defaults.DatasetNamePrefix = override.DatasetNamePrefix
+	}
+	if override.ShareNamePrefix != "" {
+		defaults.ShareNamePrefix = override.ShareNamePrefix
+	}
+}
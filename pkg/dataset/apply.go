@@ -0,0 +1,80 @@
+package dataset
+
+import (
+	"context"
+	"fmt"
+
+	datasetv1alpha1 "github.com/BaizeAI/dataset/api/dataset/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FieldManager is the server-side apply field manager modelfs applies Datasets with.
+const FieldManager = "modelfs"
+
+// applyDataset server-side applies the metadata and spec fields set on desired, so modelfs owns
+// only those fields and leaves the ones set by other actors alone. Applying unchanged fields is
+// a no-op on the server. A Dataset last written with Update, before modelfs used server-side
+// apply, is taken over with forced ownership; afterwards a conflict with another field manager
+// is returned as an error naming the conflicting fields.
+func applyDataset(ctx context.Context, c client.Client, desired, existing *datasetv1alpha1.Dataset) error {
+	desired.SetGroupVersionKind(datasetv1alpha1.GroupVersion.WithKind("Dataset"))
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(desired)
+	if err != nil {
+		return fmt.Errorf("convert dataset %s/%s: %w", desired.Namespace, desired.Name, err)
+	}
+	delete(obj, "status")
+	pruneEmpty(obj)
+
+	opts := []client.ApplyOption{client.FieldOwner(FieldManager)}
+	if existing != nil && !appliedBy(existing, FieldManager) {
+		opts = append(opts, client.ForceOwnership)
+	}
+	err = c.Apply(ctx, client.ApplyConfigurationFromUnstructured(&unstructured.Unstructured{Object: obj}), opts...)
+	if errors.IsConflict(err) {
+		return fmt.Errorf("apply dataset %s/%s: fields are managed by another field manager: %w", desired.Namespace, desired.Name, err)
+	}
+	return err
+}
+
+// appliedBy reports whether manager has applied obj with server-side apply.
+func appliedBy(obj metav1.Object, manager string) bool {
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager == manager && entry.Operation == metav1.ManagedFieldsOperationApply {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneEmpty removes null values and empty maps and lists, which a typed object carries for
+// unset fields, so that the apply configuration only claims fields modelfs actually sets.
+func pruneEmpty(obj map[string]interface{}) {
+	for key, value := range obj {
+		if isEmpty(value) {
+			delete(obj, key)
+		}
+	}
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		pruneEmpty(v)
+		return len(v) == 0
+	case []interface{}:
+		for _, item := range v {
+			if m, ok := item.(map[string]interface{}); ok {
+				pruneEmpty(m)
+			}
+		}
+		return len(v) == 0
+	default:
+		return false
+	}
+}
@@ -7,6 +7,8 @@ import (
 
 	datasetv1alpha1 "github.com/BaizeAI/dataset/api/dataset/v1alpha1"
 	modelv1 "github.com/samzong/modelfs/api/v1"
+	"github.com/samzong/modelfs/pkg/config"
+	"github.com/samzong/modelfs/pkg/upstream"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -14,34 +16,44 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// BuildDatasetSpec builds a DatasetSpec from a ModelVersion and ModelSource.
-// It reads the Secret referenced by ModelSource.secretRef and merges options (if secretRef is provided).
-func BuildDatasetSpec(ctx context.Context, c client.Client, version modelv1.ModelVersion, source modelv1.ModelSource, namespace string) (*datasetv1alpha1.DatasetSpec, error) {
+// BuildDatasetSpec builds a DatasetSpec from a ModelVersion and the spec of a ModelSource or ClusterModelSource.
+// In Embed mode it reads the Secret referenced by secretRef from secretNamespace and merges the mapped keys into options.
+func BuildDatasetSpec(ctx context.Context, c client.Client, version modelv1.ModelVersion, sourceSpec modelv1.ModelSourceSpec, secretNamespace string) (*datasetv1alpha1.DatasetSpec, error) {
 	// Convert source type
-	datasetType, err := convertSourceType(source.Spec.Type)
+	datasetType, err := convertSourceType(sourceSpec.Type)
 	if err != nil {
 		return nil, err
 	}
 
 	// Merge options: ModelSource config + Secret data (if secretRef is provided)
 	options := make(map[string]string)
-	for k, v := range source.Spec.Config {
+	for k, v := range sourceSpec.Config {
 		options[k] = v
 	}
-	// Add Secret data as options (if secretRef is provided)
-	if source.Spec.SecretRef != "" {
+	// Add mapped Secret data as options (if secretRef is provided).
+	// In Reference mode the caller points the Dataset at a Secret instead.
+	if sourceSpec.SecretRef != "" && sourceSpec.SecretMode != modelv1.SecretModeReference {
 		secret := &corev1.Secret{}
-		secretKey := types.NamespacedName{Namespace: namespace, Name: source.Spec.SecretRef}
+		secretKey := types.NamespacedName{Namespace: secretNamespace, Name: sourceSpec.SecretRef}
 		if err := c.Get(ctx, secretKey, secret); err != nil {
-			return nil, fmt.Errorf("get secret %s: %w", source.Spec.SecretRef, err)
+			return nil, fmt.Errorf("get secret %s: %w", sourceSpec.SecretRef, err)
 		}
-		for k, v := range secret.Data {
+		mapped, err := MapSecretData(sourceSpec, secret.Data)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range mapped {
 			options[k] = string(v)
 		}
 	}
 
+	// The Git loader takes the revision as an option rather than in the URI
+	if sourceSpec.Type == "GIT" {
+		setGitRevisionOption(options, version.Revision)
+	}
+
 	// Build URI from repo and revision
-	uri, err := buildDatasetURI(source, version)
+	uri, err := buildDatasetURI(sourceSpec, version)
 	if err != nil {
 		return nil, fmt.Errorf("build URI: %w", err)
 	}
@@ -67,7 +79,7 @@ func BuildDatasetSpec(ctx context.Context, c client.Client, version modelv1.Mode
 			},
 		}
 	} else {
-		// Default: ReadWriteMany, 100Ti
+		// Default: ReadWriteMany, config.DefaultStorageSize; callers may size it from the upstream files
 		volumeClaimTemplate = corev1.PersistentVolumeClaim{
 			Spec: corev1.PersistentVolumeClaimSpec{
 				AccessModes: []corev1.PersistentVolumeAccessMode{
@@ -75,7 +87,7 @@ func BuildDatasetSpec(ctx context.Context, c client.Client, version modelv1.Mode
 				},
 				Resources: corev1.VolumeResourceRequirements{
 					Requests: corev1.ResourceList{
-						corev1.ResourceStorage: mustParseResourceQuantity("100Ti"),
+						corev1.ResourceStorage: mustParseResourceQuantity(config.DefaultStorageSize),
 					},
 				},
 			},
@@ -88,12 +100,17 @@ func BuildDatasetSpec(ctx context.Context, c client.Client, version modelv1.Mode
 	}, nil
 }
 
-// EnsureDataset creates or updates a Dataset CR.
-func EnsureDataset(ctx context.Context, c client.Client, name, namespace string, spec *datasetv1alpha1.DatasetSpec, ownerRef *metav1.OwnerReference) error {
+// EnsureDataset creates or updates a Dataset CR with server-side apply.
+// The existing dataSyncRound is preserved unless spec requests a later round,
+// since the Dataset controller only starts a new sync when the round increases.
+// Fields other actors set on the Dataset are left untouched.
+func EnsureDataset(ctx context.Context, c client.Client, name, namespace string, spec *datasetv1alpha1.DatasetSpec, labels, annotations map[string]string, ownerRef *metav1.OwnerReference) error {
 	dataset := &datasetv1alpha1.Dataset{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      labels,
+			Annotations: annotations,
 		},
 		Spec: *spec,
 	}
@@ -103,28 +120,35 @@ func EnsureDataset(ctx context.Context, c client.Client, name, namespace string,
 
 	existing := &datasetv1alpha1.Dataset{}
 	key := types.NamespacedName{Name: name, Namespace: namespace}
-	err := c.Get(ctx, key, existing)
-	if err != nil {
-		if client.IgnoreNotFound(err) == nil {
-			// Create new dataset
-			return c.Create(ctx, dataset)
+	if err := c.Get(ctx, key, existing); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
 		}
-		return err
+		existing = nil
 	}
 
-	// Update existing dataset (patch spec)
-	existing.Spec = *spec
-	return c.Update(ctx, existing)
+	// Keep applying the current round, since leaving it out would drop it
+	if existing != nil && dataset.Spec.DataSyncRound < existing.Spec.DataSyncRound {
+		dataset.Spec.DataSyncRound = existing.Spec.DataSyncRound
+	}
+	return applyDataset(ctx, c, dataset, existing)
 }
 
-// EnsureReferenceDataset creates or updates a REFERENCE Dataset in the target namespace.
-func EnsureReferenceDataset(ctx context.Context, c client.Client, sourceNs, sourceDatasetName, targetNs, targetName string, labels map[string]string) error {
-	uri := fmt.Sprintf("dataset://%s/%s", sourceNs, sourceDatasetName)
+// ReferenceURI returns the source URI of a REFERENCE Dataset to the Dataset sourceNs/sourceDatasetName.
+func ReferenceURI(sourceNs, sourceDatasetName string) string {
+	return fmt.Sprintf("dataset://%s/%s", sourceNs, sourceDatasetName)
+}
+
+// EnsureReferenceDataset creates or updates a REFERENCE Dataset in the target namespace with server-side apply.
+// It refuses to take over a Dataset that is not a REFERENCE or is annotated with another model version.
+func EnsureReferenceDataset(ctx context.Context, c client.Client, sourceNs, sourceDatasetName, targetNs, targetName string, labels, annotations map[string]string, ownerRef *metav1.OwnerReference) error {
+	uri := ReferenceURI(sourceNs, sourceDatasetName)
 	dataset := &datasetv1alpha1.Dataset{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      targetName,
-			Namespace: targetNs,
-			Labels:    labels,
+			Name:        targetName,
+			Namespace:   targetNs,
+			Labels:      labels,
+			Annotations: annotations,
 		},
 		Spec: datasetv1alpha1.DatasetSpec{
 			Source: datasetv1alpha1.DatasetSource{
@@ -133,38 +157,48 @@ func EnsureReferenceDataset(ctx context.Context, c client.Client, sourceNs, sour
 			},
 		},
 	}
+	if ownerRef != nil {
+		dataset.OwnerReferences = []metav1.OwnerReference{*ownerRef}
+	}
 
 	existing := &datasetv1alpha1.Dataset{}
 	key := types.NamespacedName{Name: targetName, Namespace: targetNs}
-	err := c.Get(ctx, key, existing)
-	if err != nil {
-		if client.IgnoreNotFound(err) == nil {
-			return c.Create(ctx, dataset)
+	if err := c.Get(ctx, key, existing); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
 		}
-		return err
+		return applyDataset(ctx, c, dataset, nil)
 	}
-
-	// Update existing reference dataset
-	existing.Spec = dataset.Spec
-	if existing.Labels == nil {
-		existing.Labels = make(map[string]string)
+	if existing.Spec.Source.Type != datasetv1alpha1.DatasetTypeReference {
+		return fmt.Errorf("dataset %s/%s exists and is not a REFERENCE", targetNs, targetName)
 	}
-	for k, v := range labels {
-		existing.Labels[k] = v
+	for key, value := range annotations {
+		if current, ok := existing.Annotations[key]; ok && current != value {
+			return fmt.Errorf("dataset %s/%s belongs to %s %q", targetNs, targetName, key, current)
+		}
 	}
-	return c.Update(ctx, existing)
+	return applyDataset(ctx, c, dataset, existing)
 }
 
-// GetDatasetName returns the Dataset name for a model version.
-// Format: mdl-<model>-<version>
-func GetDatasetName(modelName, versionName string) string {
-	return fmt.Sprintf("mdl-%s-%s", modelName, versionName)
+// supportedConfigKeys lists the ModelSource config keys honored for each source type.
+// It covers the keys read by buildDatasetURI plus the loader options of the DatasetType,
+// and "endpoint", which also points revision lookups and probes at the source API.
+var supportedConfigKeys = map[string][]string{
+	"GIT":          {"uri", "url", "endpoint", "branch", "commit", "depth", "submodules"},
+	"S3":           {"uri", "url", "provider", "region", "endpoint", "syncMode"},
+	"HTTP":         {"uri", "url", "endpoint", "syncMode"},
+	"PVC":          {"uri", "pvcName", "path"},
+	"NFS":          {"uri", "server", "path"},
+	"CONDA":        {"uri", "name", "pythonVersion", "pipIndexUrl", "pipExtraIndexUrl", "condaEnvironmentYml", "pipRequirementsTxt", "gpuType"},
+	"REFERENCE":    {"uri"},
+	"HUGGING_FACE": {"uri", "endpoint", "repoType", "include", "exclude", "offline"},
+	"MODEL_SCOPE":  {"uri", "endpoint", "repoType", "include", "exclude"},
 }
 
-// GetReferenceDatasetName returns the REFERENCE Dataset name for sharing.
-// Format: share-<source-ns>-<model>-<version>
-func GetReferenceDatasetName(sourceNs, modelName, versionName string) string {
-	return fmt.Sprintf("share-%s-%s-%s", sourceNs, modelName, versionName)
+// SupportedConfigKeys returns the ModelSource config keys accepted for a source type.
+// It returns nil for unsupported source types.
+func SupportedConfigKeys(sourceType string) []string {
+	return supportedConfigKeys[sourceType]
 }
 
 func convertSourceType(modelType string) (datasetv1alpha1.DatasetType, error) {
@@ -192,34 +226,34 @@ func convertSourceType(modelType string) (datasetv1alpha1.DatasetType, error) {
 	}
 }
 
-func buildDatasetURI(source modelv1.ModelSource, version modelv1.ModelVersion) (string, error) {
+func buildDatasetURI(sourceSpec modelv1.ModelSourceSpec, version modelv1.ModelVersion) (string, error) {
 	repo := version.Repo
 	revision := version.Revision
 	if revision == "" {
-		revision = "main"
+		revision = config.DefaultRevision
 	}
 
 	// Extract URI from ModelSource config if provided
-	if uri, ok := source.Spec.Config["uri"]; ok {
+	if uri, ok := sourceSpec.Config["uri"]; ok {
 		return uri, nil
 	}
 
 	// Build URI based on type
-	switch source.Spec.Type {
+	switch sourceSpec.Type {
 	case "HTTP", "S3", "GIT":
 		if repo != "" {
 			return repo, nil
 		}
-		if urlStr, ok := source.Spec.Config["url"]; ok {
+		if urlStr, ok := sourceSpec.Config["url"]; ok {
 			return urlStr, nil
 		}
-		return "", fmt.Errorf("missing repo in ModelVersion or url in ModelSource config for type %s", source.Spec.Type)
+		return "", fmt.Errorf("missing repo in ModelVersion or url in ModelSource config for type %s", sourceSpec.Type)
 	case "HUGGING_FACE":
 		if repo == "" {
 			return "", fmt.Errorf("missing repo in ModelVersion for HuggingFace")
 		}
 		uri := fmt.Sprintf("huggingface://%s", repo)
-		if revision != "main" {
+		if revision != config.DefaultRevision {
 			uri = fmt.Sprintf("%s@%s", uri, revision)
 		}
 		return uri, nil
@@ -228,15 +262,15 @@ func buildDatasetURI(source modelv1.ModelSource, version modelv1.ModelVersion) (
 			return "", fmt.Errorf("missing repo in ModelVersion for ModelScope")
 		}
 		uri := fmt.Sprintf("modelscope://%s", repo)
-		if revision != "main" {
+		if revision != config.DefaultRevision {
 			uri = fmt.Sprintf("%s@%s", uri, revision)
 		}
 		return uri, nil
 	case "PVC":
-		if pvcName, ok := source.Spec.Config["pvcName"]; ok {
+		if pvcName, ok := sourceSpec.Config["pvcName"]; ok {
 			path := repo
 			if path == "" {
-				path = source.Spec.Config["path"]
+				path = sourceSpec.Config["path"]
 			}
 			if path == "" {
 				path = "/"
@@ -245,10 +279,10 @@ func buildDatasetURI(source modelv1.ModelSource, version modelv1.ModelVersion) (
 		}
 		return "", fmt.Errorf("missing pvcName in ModelSource config for PVC")
 	case "NFS":
-		if server, ok := source.Spec.Config["server"]; ok {
+		if server, ok := sourceSpec.Config["server"]; ok {
 			path := repo
 			if path == "" {
-				path = source.Spec.Config["path"]
+				path = sourceSpec.Config["path"]
 			}
 			if path == "" {
 				path = "/"
@@ -261,7 +295,20 @@ func buildDatasetURI(source modelv1.ModelSource, version modelv1.ModelVersion) (
 		}
 		return "", fmt.Errorf("missing server in ModelSource config for NFS")
 	default:
-		return "", fmt.Errorf("cannot build URI for type %s", source.Spec.Type)
+		return "", fmt.Errorf("cannot build URI for type %s", sourceSpec.Type)
+	}
+}
+
+// setGitRevisionOption maps a version revision to the commit or branch option,
+// unless the ModelSource config already sets one.
+func setGitRevisionOption(options map[string]string, revision string) {
+	if revision == "" || options["commit"] != "" || options["branch"] != "" {
+		return
+	}
+	if upstream.IsCommitSHA(revision) {
+		options["commit"] = revision
+	} else if revision != config.DefaultRevision {
+		options["branch"] = revision
 	}
 }
 
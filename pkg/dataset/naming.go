@@ -0,0 +1,177 @@
+package dataset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// MaxNameLength is the length limit of generated Dataset names. The Dataset controller
+	// derives PVC and Job names from them, so they are kept to a DNS-1123 label.
+	MaxNameLength = validation.DNS1123LabelMaxLength
+
+	// LabelModelNamespace, LabelModel and LabelVersion select the Datasets of a model version.
+	// Values too long for a label are truncated with a hash suffix; the annotations with the
+	// same keys hold the exact names.
+	LabelModelNamespace = "modelfs.samzong.dev/model-namespace"
+	LabelModel          = "modelfs.samzong.dev/model"
+	LabelVersion        = "modelfs.samzong.dev/version"
+
+	// AnnotationModelNamespace, AnnotationModel and AnnotationVersion record the exact
+	// namespace, model and version a Dataset belongs to.
+	AnnotationModelNamespace = LabelModelNamespace
+	AnnotationModel          = LabelModel
+	AnnotationVersion        = LabelVersion
+
+	// LabelShareCopy marks the VolumeSnapshots, VolumeSnapshotContents and PVCs of Copy shares.
+	LabelShareCopy = "modelfs.samzong.dev/share-copy"
+	// AnnotationSourceSnapshot records the VolumeSnapshot a Copy share was restored from.
+	AnnotationSourceSnapshot = "modelfs.samzong.dev/source-snapshot"
+
+	// LabelRetained marks the PersistentVolumes kept by the Retain deletion policy and the Datasets
+	// kept by the Orphan policy, which a re-added version adopts again.
+	LabelRetained = "modelfs.samzong.dev/retained"
+	// AnnotationReclaimPolicy records the reclaim policy a retained PersistentVolume had before it
+	// was switched to Retain, restored when a version adopts the volume again.
+	AnnotationReclaimPolicy = "modelfs.samzong.dev/reclaim-policy"
+
+	nameHashLength = 8
+)
+
+// GetDatasetName returns the legacy Dataset name for a model version.
+// Format: mdl-<model>-<version>
+func GetDatasetName(modelName, versionName string) string {
+	return fmt.Sprintf("mdl-%s-%s", modelName, versionName)
+}
+
+// GetVersionDatasetName returns the Dataset name for a model version spec.
+// Format: <prefix><model>-<version>-<hash>, where the prefix defaults to "mdl-" and the hash
+// covers the model, version and spec hash. The readable part is truncated to fit MaxNameLength.
+func GetVersionDatasetName(prefix, modelName, versionName, versionHash string) string {
+	return boundedName(MaxNameLength, prefix, []string{modelName, versionName}, modelName, versionName, versionHash)
+}
+
+// GetLegacyVersionDatasetName returns the name GetVersionDatasetName returned before names were bounded.
+// Format: <prefix><model>-<version>-<version-hash>
+func GetLegacyVersionDatasetName(prefix, modelName, versionName, versionHash string) string {
+	return fmt.Sprintf("%s%s-%s-%s", prefix, modelName, versionName, versionHash)
+}
+
+// GetReferenceDatasetName returns the REFERENCE Dataset name for sharing.
+// Format: <prefix><source-ns>-<model>-<version>-<hash>, where the prefix defaults to "share-"
+// and the hash covers the source namespace, model and version, so that names split
+// differently at dashes cannot collide.
+func GetReferenceDatasetName(prefix, sourceNs, modelName, versionName string) string {
+	return boundedName(MaxNameLength, prefix, []string{sourceNs, modelName, versionName}, sourceNs, modelName, versionName)
+}
+
+// GetLegacyReferenceDatasetName returns the name GetReferenceDatasetName returned before names were bounded.
+// Format: <prefix><source-ns>-<model>-<version>
+func GetLegacyReferenceDatasetName(prefix, sourceNs, modelName, versionName string) string {
+	return fmt.Sprintf("%s%s-%s-%s", prefix, sourceNs, modelName, versionName)
+}
+
+// GetShareSnapshotName returns the name of the VolumeSnapshot Copy shares of a version are restored from.
+// Format: snap-<model>-<version>-<hash>, where the hash covers the model, version and the snapshotted
+// Dataset, so a new active Dataset gets a new snapshot.
+func GetShareSnapshotName(modelName, versionName, sourceDataset string) string {
+	return boundedName(MaxNameLength, "snap-", []string{modelName, versionName}, modelName, versionName, sourceDataset)
+}
+
+// GetShareSnapshotContentName returns the name of the cluster-scoped VolumeSnapshotContent that
+// imports a snapshot into the target namespace under the given VolumeSnapshot name.
+// Format: <target-ns>-<name>-<hash>
+func GetShareSnapshotContentName(targetNs, name string) string {
+	return boundedName(MaxNameLength, "", []string{targetNs, name}, targetNs, name)
+}
+
+// GetDatasetSecretName returns the name of the Secret derived for the Datasets of a model.
+// Format: <prefix><model>-credentials, where the prefix defaults to "mdl-". Model names are
+// unique in a namespace, so only names over the Secret name limit get a hash suffix.
+func GetDatasetSecretName(prefix, modelName string) string {
+	name := fmt.Sprintf("%s%s-credentials", prefix, modelName)
+	if len(name) <= validation.DNS1123SubdomainMaxLength {
+		return name
+	}
+	return boundedName(validation.DNS1123SubdomainMaxLength, prefix, []string{modelName, "credentials"}, modelName)
+}
+
+// Labels returns the labels that select the Datasets of a model version.
+// The version label is omitted when versionName is empty.
+func Labels(modelNamespace, modelName, versionName string) map[string]string {
+	labels := map[string]string{
+		LabelModelNamespace: LabelValue(modelNamespace),
+		LabelModel:          LabelValue(modelName),
+	}
+	if versionName != "" {
+		labels[LabelVersion] = LabelValue(versionName)
+	}
+	return labels
+}
+
+// Annotations returns the annotations that record the exact model version of a Dataset.
+func Annotations(modelNamespace, modelName, versionName string) map[string]string {
+	annotations := map[string]string{
+		AnnotationModelNamespace: modelNamespace,
+		AnnotationModel:          modelName,
+	}
+	if versionName != "" {
+		annotations[AnnotationVersion] = versionName
+	}
+	return annotations
+}
+
+// IsManagedBy reports whether a Dataset's annotations name the model, and the version unless versionName is empty.
+// Labels alone are not enough, since truncated label values may be shared by different names.
+func IsManagedBy(obj metav1.Object, modelNamespace, modelName, versionName string) bool {
+	annotations := obj.GetAnnotations()
+	if annotations[AnnotationModelNamespace] != modelNamespace || annotations[AnnotationModel] != modelName {
+		return false
+	}
+	return versionName == "" || annotations[AnnotationVersion] == versionName
+}
+
+// ModelOf returns the namespace and name of the model recorded on a Dataset.
+func ModelOf(obj metav1.Object) (namespace, name string, ok bool) {
+	annotations := obj.GetAnnotations()
+	namespace, name = annotations[AnnotationModelNamespace], annotations[AnnotationModel]
+	return namespace, name, namespace != "" && name != ""
+}
+
+// LabelValue returns value if it fits in a label value, or else a truncated value with a hash suffix.
+func LabelValue(value string) string {
+	if len(validation.IsValidLabelValue(value)) == 0 {
+		return value
+	}
+	return boundedName(validation.LabelValueMaxLength, "", []string{value}, value)
+}
+
+// boundedName joins prefix and parts with dashes, truncated so that a dash and a hash of
+// identity fit within max. Dots become dashes so the name is also a valid DNS label.
+// The hash keeps names unique however the parts are split.
+func boundedName(max int, prefix string, parts []string, identity ...string) string {
+	hash := nameHash(identity...)
+	readable := strings.ReplaceAll(prefix+strings.Join(parts, "-"), ".", "-")
+	if limit := max - len(hash) - 1; len(readable) > limit {
+		readable = strings.TrimRight(readable[:limit], "-._")
+	}
+	if readable == "" {
+		return hash
+	}
+	return readable + "-" + hash
+}
+
+// nameHash returns a short, stable hash of values.
+func nameHash(values ...string) string {
+	h := sha256.New()
+	for _, value := range values {
+		h.Write([]byte(value))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:nameHashLength]
+}
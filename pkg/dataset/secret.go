@@ -0,0 +1,136 @@
+package dataset
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// credentialKeySets lists, for each source type, the alternative sets of Secret keys the
+// Dataset loader accepts as credentials. A Secret must contain every key of at least one set.
+var credentialKeySets = map[string][][]string{
+	"HUGGING_FACE": {{"token"}},
+	"MODEL_SCOPE":  {{"token"}},
+	"S3":           {{"access-key", "secret-key"}},
+	"GIT":          {{"username", "password"}, {"token"}, {"ssh-privatekey"}},
+	"HTTP":         {{"username", "password"}, {"token"}},
+}
+
+// optionalCredentialKeys lists credential keys that are used when present but never required.
+var optionalCredentialKeys = map[string][]string{
+	"GIT": {"ssh-privatekey-passphrase"},
+}
+
+// CredentialKeys returns the Secret keys read as credentials for a source type.
+func CredentialKeys(sourceType string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, set := range credentialKeySets[sourceType] {
+		for _, key := range set {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	keys = append(keys, optionalCredentialKeys[sourceType]...)
+	return keys
+}
+
+// MapSecretData selects the Secret data passed on to the Dataset.
+// With spec.secretKeys set, each listed Secret key is renamed to its option name and
+// every listed key must exist. Otherwise only the credential keys of the source type are kept.
+func MapSecretData(spec modelv1.ModelSourceSpec, data map[string][]byte) (map[string][]byte, error) {
+	mapped := make(map[string][]byte)
+	if len(spec.SecretKeys) > 0 {
+		for secretKey, option := range spec.SecretKeys {
+			value, ok := data[secretKey]
+			if !ok {
+				return nil, fmt.Errorf("secret %s has no key %q", spec.SecretRef, secretKey)
+			}
+			mapped[option] = value
+		}
+		return mapped, nil
+	}
+
+	for _, key := range CredentialKeys(spec.Type) {
+		if value, ok := data[key]; ok {
+			mapped[key] = value
+		}
+	}
+	return mapped, nil
+}
+
+// ValidateCredentials checks that mapped Secret data satisfies one of the credential key sets of a source type.
+func ValidateCredentials(sourceType string, mapped map[string][]byte) error {
+	sets := credentialKeySets[sourceType]
+	if len(sets) == 0 {
+		return nil
+	}
+
+	alternatives := make([]string, 0, len(sets))
+	for _, set := range sets {
+		complete := true
+		for _, key := range set {
+			if len(mapped[key]) == 0 {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			return nil
+		}
+		alternatives = append(alternatives, strings.Join(set, "+"))
+	}
+	return fmt.Errorf("%s credentials require keys %s", sourceType, strings.Join(alternatives, " or "))
+}
+
+// EnsureDatasetSecret creates or updates the Secret a Dataset references in Reference mode.
+// It returns a conflict error rather than overwrite an existing Secret that ownerRef does not control.
+func EnsureDatasetSecret(ctx context.Context, c client.Client, name, namespace string, data map[string][]byte, ownerRef *metav1.OwnerReference) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+	if ownerRef != nil {
+		secret.OwnerReferences = []metav1.OwnerReference{*ownerRef}
+	}
+
+	existing := &corev1.Secret{}
+	key := types.NamespacedName{Name: name, Namespace: namespace}
+	err := c.Get(ctx, key, existing)
+	if err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			return c.Create(ctx, secret)
+		}
+		return err
+	}
+
+	if ownerRef != nil {
+		if controller := metav1.GetControllerOf(existing); controller == nil || controller.UID != ownerRef.UID {
+			return errors.NewConflict(corev1.Resource("secrets"), name,
+				fmt.Errorf("secret %s/%s exists and is not owned by %s %s", namespace, name, ownerRef.Kind, ownerRef.Name))
+		}
+	}
+	existing.Data = data
+	return c.Update(ctx, existing)
+}
+
+// SecretOptionNames returns the option names accepted as secretKeys targets for a source type.
+func SecretOptionNames(sourceType string) []string {
+	names := append(CredentialKeys(sourceType), SupportedConfigKeys(sourceType)...)
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,293 @@
+// Package snapshot creates the CSI VolumeSnapshots and restored PVCs behind Copy shares.
+// VolumeSnapshots are handled as unstructured objects so the operator does not depend on
+// the external-snapshotter client. Objects are read through the reader passed in, which
+// callers point at the API server, since VolumeSnapshots are not cached.
+package snapshot
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Group is the API group of CSI snapshot objects.
+const Group = "snapshot.storage.k8s.io"
+
+// ErrNotInstalled is returned when the cluster does not serve the VolumeSnapshot CRDs.
+var ErrNotInstalled = goerrors.New("VolumeSnapshot CRDs not installed")
+
+var (
+	// VolumeSnapshotGVK is the kind of a namespaced VolumeSnapshot.
+	VolumeSnapshotGVK = schema.GroupVersionKind{Group: Group, Version: "v1", Kind: "VolumeSnapshot"}
+	// VolumeSnapshotContentGVK is the kind of a cluster-scoped VolumeSnapshotContent.
+	VolumeSnapshotContentGVK = schema.GroupVersionKind{Group: Group, Version: "v1", Kind: "VolumeSnapshotContent"}
+)
+
+// Status is the observed state of a VolumeSnapshot.
+type Status struct {
+	// ReadyToUse is true once the snapshot can be restored.
+	ReadyToUse bool
+	// ContentName is the VolumeSnapshotContent the snapshot is bound to.
+	ContentName string
+	// RestoreSize is the minimum size of a volume restored from the snapshot.
+	RestoreSize *resource.Quantity
+	// Error is the last error the snapshot controller reported.
+	Error string
+	// Terminating is true while the snapshot is being deleted.
+	Terminating bool
+}
+
+// Content is what a snapshot taken in one namespace needs to be imported into another.
+type Content struct {
+	Driver         string
+	SnapshotHandle string
+	ClassName      string
+}
+
+// EnsureSnapshot creates a VolumeSnapshot of a PVC unless it exists, and returns its status.
+// An empty className uses the default VolumeSnapshotClass of the driver.
+func EnsureSnapshot(ctx context.Context, c client.Client, reader client.Reader, namespace, name, pvcName, className string, labels, annotations map[string]string, ownerRef *metav1.OwnerReference) (*Status, error) {
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{"persistentVolumeClaimName": pvcName},
+	}
+	if className != "" {
+		spec["volumeSnapshotClassName"] = className
+	}
+	obj := newObject(VolumeSnapshotGVK, namespace, name, labels, annotations, spec)
+	if ownerRef != nil {
+		obj.SetOwnerReferences([]metav1.OwnerReference{*ownerRef})
+	}
+	existing, err := ensure(ctx, c, reader, obj)
+	if err != nil {
+		return nil, err
+	}
+	if source, _, _ := unstructured.NestedString(existing.Object, "spec", "source", "persistentVolumeClaimName"); source != pvcName {
+		return nil, fmt.Errorf("volumesnapshot %s/%s exists and is not a snapshot of PVC %s", namespace, name, pvcName)
+	}
+	return statusOf(existing), nil
+}
+
+// EnsureImportedSnapshot makes a snapshot available in another namespace: a pre-provisioned
+// VolumeSnapshotContent with the snapshot handle of content, bound to a VolumeSnapshot of the
+// same name in namespace. The content is retained when deleted, so that the snapshot itself stays
+// owned by the source VolumeSnapshot. It returns the status of the imported VolumeSnapshot.
+func EnsureImportedSnapshot(ctx context.Context, c client.Client, reader client.Reader, namespace, name, contentName string, content *Content, labels, annotations map[string]string) (*Status, error) {
+	contentSpec := map[string]interface{}{
+		"deletionPolicy": "Retain",
+		"driver":         content.Driver,
+		"source":         map[string]interface{}{"snapshotHandle": content.SnapshotHandle},
+		"volumeSnapshotRef": map[string]interface{}{
+			"namespace": namespace,
+			"name":      name,
+		},
+	}
+	if content.ClassName != "" {
+		contentSpec["volumeSnapshotClassName"] = content.ClassName
+	}
+	existingContent, err := ensure(ctx, c, reader, newObject(VolumeSnapshotContentGVK, "", contentName, labels, annotations, contentSpec))
+	if err != nil {
+		return nil, err
+	}
+	if handle, _, _ := unstructured.NestedString(existingContent.Object, "spec", "source", "snapshotHandle"); handle != content.SnapshotHandle {
+		// The content of a replaced snapshot must be deleted before it is imported again
+		return &Status{Terminating: true}, nil
+	}
+
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{"volumeSnapshotContentName": contentName},
+	}
+	if content.ClassName != "" {
+		spec["volumeSnapshotClassName"] = content.ClassName
+	}
+	existing, err := ensure(ctx, c, reader, newObject(VolumeSnapshotGVK, namespace, name, labels, annotations, spec))
+	if err != nil {
+		return nil, err
+	}
+	return statusOf(existing), nil
+}
+
+// GetContent returns the driver, handle and class of the VolumeSnapshotContent a snapshot is bound to.
+func GetContent(ctx context.Context, reader client.Reader, name string) (*Content, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(VolumeSnapshotContentGVK)
+	if err := reader.Get(ctx, types.NamespacedName{Name: name}, obj); err != nil {
+		return nil, notInstalled(err)
+	}
+	content := &Content{}
+	content.Driver, _, _ = unstructured.NestedString(obj.Object, "spec", "driver")
+	content.ClassName, _, _ = unstructured.NestedString(obj.Object, "spec", "volumeSnapshotClassName")
+	content.SnapshotHandle, _, _ = unstructured.NestedString(obj.Object, "status", "snapshotHandle")
+	if content.SnapshotHandle == "" {
+		return nil, fmt.Errorf("volumesnapshotcontent %s has no snapshot handle yet", name)
+	}
+	return content, nil
+}
+
+// EnsureRestoredPVC creates a read-only PVC restored from the VolumeSnapshot of the same name
+// unless it exists. The PVC requests ReadOnlyMany, takes its storage class and volume mode from
+// template and is sized to the larger of template's request and restoreSize.
+func EnsureRestoredPVC(ctx context.Context, c client.Client, reader client.Reader, namespace, name string, template *corev1.PersistentVolumeClaim, restoreSize *resource.Quantity, labels, annotations map[string]string) (*corev1.PersistentVolumeClaim, error) {
+	existing := &corev1.PersistentVolumeClaim{}
+	err := reader.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, existing)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	size := template.Spec.Resources.Requests[corev1.ResourceStorage]
+	if restoreSize != nil && restoreSize.Cmp(size) > 0 {
+		size = *restoreSize
+	}
+	group := Group
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany},
+			StorageClassName: template.Spec.StorageClassName,
+			VolumeMode:       template.Spec.VolumeMode,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: &group,
+				Kind:     VolumeSnapshotGVK.Kind,
+				Name:     name,
+			},
+		},
+	}
+	if err := c.Create(ctx, pvc); err != nil {
+		return nil, err
+	}
+	return pvc, nil
+}
+
+// IsReadOnly reports whether a PVC only requests ReadOnlyMany access.
+func IsReadOnly(pvc *corev1.PersistentVolumeClaim) bool {
+	return len(pvc.Spec.AccessModes) == 1 && pvc.Spec.AccessModes[0] == corev1.ReadOnlyMany
+}
+
+// ProvisioningError returns the message of the latest ProvisioningFailed event of a pending PVC,
+// e.g. from a driver that cannot restore a snapshot ReadOnlyMany, or "" if there is none.
+func ProvisioningError(ctx context.Context, reader client.Reader, pvc *corev1.PersistentVolumeClaim) (string, error) {
+	if pvc.Status.Phase != corev1.ClaimPending {
+		return "", nil
+	}
+	events := &corev1.EventList{}
+	if err := reader.List(ctx, events, client.InNamespace(pvc.Namespace), client.MatchingFields{
+		"involvedObject.kind": "PersistentVolumeClaim",
+		"involvedObject.name": pvc.Name,
+		"involvedObject.uid":  string(pvc.UID),
+	}); err != nil {
+		return "", err
+	}
+	var latest *corev1.Event
+	for i := range events.Items {
+		event := &events.Items[i]
+		if event.Type != corev1.EventTypeWarning || event.Reason != "ProvisioningFailed" {
+			continue
+		}
+		if latest == nil || eventTime(latest).Before(eventTime(event)) {
+			latest = event
+		}
+	}
+	if latest == nil {
+		return "", nil
+	}
+	return latest.Message, nil
+}
+
+func eventTime(event *corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	return event.EventTime.Time
+}
+
+// DeleteSnapshot deletes a VolumeSnapshot, ignoring one that does not exist.
+func DeleteSnapshot(ctx context.Context, c client.Client, namespace, name string) error {
+	return deleteObject(ctx, c, VolumeSnapshotGVK, namespace, name)
+}
+
+// DeleteContent deletes a VolumeSnapshotContent, ignoring one that does not exist.
+func DeleteContent(ctx context.Context, c client.Client, name string) error {
+	return deleteObject(ctx, c, VolumeSnapshotContentGVK, "", name)
+}
+
+func newObject(gvk schema.GroupVersionKind, namespace, name string, labels, annotations map[string]string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetLabels(labels)
+	obj.SetAnnotations(annotations)
+	return obj
+}
+
+// ensure creates obj unless it exists and returns the object in the cluster.
+// Snapshot specs are immutable, so an existing object is returned as is.
+func ensure(ctx context.Context, c client.Client, reader client.Reader, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+	err := reader.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, existing)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, notInstalled(err)
+	}
+	if err := c.Create(ctx, obj); err != nil {
+		return nil, notInstalled(err)
+	}
+	return obj, nil
+}
+
+// notInstalled wraps the error of a cluster without the snapshot CRDs in ErrNotInstalled.
+func notInstalled(err error) error {
+	if meta.IsNoMatchError(err) {
+		return fmt.Errorf("%w: %v", ErrNotInstalled, err)
+	}
+	return err
+}
+
+func deleteObject(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, namespace, name string) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	// Without the snapshot CRDs there is nothing to delete
+	if err := c.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		return err
+	}
+	return nil
+}
+
+func statusOf(obj *unstructured.Unstructured) *Status {
+	status := &Status{Terminating: obj.GetDeletionTimestamp() != nil}
+	status.ReadyToUse, _, _ = unstructured.NestedBool(obj.Object, "status", "readyToUse")
+	status.ContentName, _, _ = unstructured.NestedString(obj.Object, "status", "boundVolumeSnapshotContentName")
+	status.Error, _, _ = unstructured.NestedString(obj.Object, "status", "error", "message")
+	if size, ok, _ := unstructured.NestedString(obj.Object, "status", "restoreSize"); ok {
+		if q, err := resource.ParseQuantity(size); err == nil {
+			status.RestoreSize = &q
+		}
+	}
+	return status
+}
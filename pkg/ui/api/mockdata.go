@@ -36,6 +36,9 @@ var sampleModelDetails = map[string]ModelDetail{
 			{Name: "fp16", Repo: "qwen/Qwen3-7B", DesiredState: "PRESENT", ShareEnabled: true, DatasetPhase: PhaseReady, PVCName: "mdl-qwen3-7b-fp16"},
 			{Name: "int4", Repo: "qwen/Qwen3-7B", DesiredState: "PRESENT", ShareEnabled: false, DatasetPhase: PhasePending},
 		},
+		ShareTargets: []ShareTargetView{
+			{Version: "fp16", Namespace: "team-a", Dataset: "share-model-system-qwen3-7b-fp16-1c2d3e4f", Phase: PhaseReady, State: "Shared"},
+		},
 	},
 }
 
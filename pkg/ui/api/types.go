@@ -25,17 +25,30 @@ type ModelSummary struct {
 }
 
 type ModelVersionView struct {
-	Name            string `json:"name"`
-	Repo            string `json:"repo"`
-	Revision        string `json:"revision,omitempty"`
-	Precision       string `json:"precision,omitempty"`
-	DesiredState    string `json:"desiredState"`
-	ShareEnabled    bool   `json:"shareEnabled"`
-	NamespacePolicy string `json:"namespacePolicy,omitempty"`
-	DatasetPhase    Phase  `json:"datasetPhase"`
-	PVCName         string `json:"pvcName,omitempty"`
-	ObservedHash    string `json:"observedHash,omitempty"`
-	ObservedStorage string `json:"observedStorage,omitempty"`
+	Name             string `json:"name"`
+	Repo             string `json:"repo"`
+	Revision         string `json:"revision,omitempty"`
+	ResolvedRevision string `json:"resolvedRevision,omitempty"`
+	Precision        string `json:"precision,omitempty"`
+	DesiredState     string `json:"desiredState"`
+	ShareEnabled     bool   `json:"shareEnabled"`
+	NamespacePolicy  string `json:"namespacePolicy,omitempty"`
+	DatasetPhase     Phase  `json:"datasetPhase"`
+	PVCName          string `json:"pvcName,omitempty"`
+	ObservedHash     string `json:"observedHash,omitempty"`
+	ObservedStorage  string `json:"observedStorage,omitempty"`
+}
+
+// ShareTargetView is a namespace a model version is shared into.
+type ShareTargetView struct {
+	Version   string `json:"version"`
+	Namespace string `json:"namespace"`
+	Dataset   string `json:"dataset,omitempty"`
+	PVCName   string `json:"pvcName,omitempty"`
+	Phase     Phase  `json:"phase"`
+	State     string `json:"state,omitempty"`
+	ExpiresAt string `json:"expiresAt,omitempty"`
+	LastError string `json:"lastError,omitempty"`
 }
 
 type ModelDetail struct {
@@ -43,7 +56,7 @@ type ModelDetail struct {
 	Description    string             `json:"description,omitempty"`
 	LogoURL        string             `json:"logoURL,omitempty"`
 	Versions       []ModelVersionView `json:"versions"`
-	ShareTargets   []string           `json:"shareTargets,omitempty"`
+	ShareTargets   []ShareTargetView  `json:"shareTargets,omitempty"`
 	ConditionsJSON string             `json:"conditionsJson,omitempty"`
 }
 
@@ -3,6 +3,7 @@ package kube
 import (
 	modelv1 "github.com/samzong/modelfs/api/v1"
 	"github.com/samzong/modelfs/pkg/ui/api"
+	corev1 "k8s.io/api/core/v1"
 	"time"
 )
 
@@ -27,7 +28,7 @@ func summarizeModel(m *modelv1.Model) api.ModelSummary {
 	return api.ModelSummary{
 		Name:          m.Name,
 		Namespace:     m.Namespace,
-		SourceRef:     m.Spec.SourceRef,
+		SourceRef:     m.Spec.SourceRef.String(),
 		Tags:          tags,
 		VersionsReady: ready,
 		VersionsTotal: total,
@@ -39,6 +40,7 @@ func summarizeModel(m *modelv1.Model) api.ModelSummary {
 func modelDetail(m *modelv1.Model) api.ModelDetail {
 	summary := summarizeModel(m)
 	versions := make([]api.ModelVersionView, 0, len(m.Spec.Versions))
+	var shareTargets []api.ShareTargetView
 	for _, v := range m.Spec.Versions {
 		vv := api.ModelVersionView{
 			Name:         v.Name,
@@ -53,10 +55,30 @@ func modelDetail(m *modelv1.Model) api.ModelDetail {
 			if sv.Name == v.Name {
 				vv.DatasetPhase = toPhase(sv.Phase)
 				vv.PVCName = sv.PVCName
+				vv.ResolvedRevision = sv.ResolvedRevision
 				vv.ObservedHash = sv.ObservedVersionHash
 				if sv.ObservedStorage != nil {
 					vv.ObservedStorage = sv.ObservedStorage.String()
 				}
+				for _, t := range sv.ShareTargets {
+					view := api.ShareTargetView{
+						Version:   v.Name,
+						Namespace: t.Namespace,
+						Dataset:   t.Dataset,
+						PVCName:   t.PVCName,
+						Phase:     toPhase(t.Phase),
+						State:     string(t.State),
+						LastError: t.LastError,
+					}
+					if t.PVCName != "" && t.Phase == string(corev1.ClaimBound) {
+						// A Copy share is ready once its restored PVC is bound
+						view.Phase = api.PhaseReady
+					}
+					if t.ExpiresAt != nil {
+						view.ExpiresAt = t.ExpiresAt.UTC().Format(time.RFC3339)
+					}
+					shareTargets = append(shareTargets, view)
+				}
 				break
 			}
 		}
@@ -66,7 +88,7 @@ func modelDetail(m *modelv1.Model) api.ModelDetail {
 	if m.Spec.Display != nil {
 		desc = m.Spec.Display.Description
 	}
-	return api.ModelDetail{Summary: summary, Description: desc, Versions: versions}
+	return api.ModelDetail{Summary: summary, Description: desc, Versions: versions, ShareTargets: shareTargets}
 }
 
 func toPhase(p string) api.Phase {
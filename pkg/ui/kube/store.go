@@ -226,7 +226,7 @@ func lastConditionTime(conditions []metav1.Condition) time.Time {
 
 func reconcileError(model *modelv1.Model) (message string, reason string, retryAt time.Time) {
 	for _, c := range model.Status.Conditions {
-		if c.Type == "ReconcileError" && c.Status == metav1.ConditionFalse {
+		if c.Type == modelv1.ModelConditionReconcileError && c.Status == metav1.ConditionFalse {
 			return c.Message, c.Reason, c.LastTransitionTime.Time.Add(1 * time.Minute)
 		}
 	}
@@ -290,7 +290,29 @@ func (s *Store) TriggerResync(ctx context.Context, namespace, modelName string)
 	if model.Annotations == nil {
 		model.Annotations = map[string]string{}
 	}
-	model.Annotations["modelfs.samzong.dev/resyncAt"] = time.Now().UTC().Format(time.RFC3339Nano)
+	model.Annotations[modelv1.ResyncAnnotation] = time.Now().UTC().Format(time.RFC3339Nano)
+	return s.client.Update(ctx, model)
+}
+
+func (s *Store) TriggerVersionResync(ctx context.Context, namespace, modelName, versionName string) error {
+	model := &modelv1.Model{}
+	if err := s.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: modelName}, model); err != nil {
+		return err
+	}
+	found := false
+	for i := range model.Spec.Versions {
+		if model.Spec.Versions[i].Name == versionName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("version %s not found on model %s", versionName, modelName)
+	}
+	if model.Annotations == nil {
+		model.Annotations = map[string]string{}
+	}
+	model.Annotations[modelv1.VersionResyncAnnotationPrefix+versionName] = time.Now().UTC().Format(time.RFC3339Nano)
 	return s.client.Update(ctx, model)
 }
 
@@ -70,6 +70,9 @@ func (m *mockStore) ToggleVersionShare(ctx context.Context, namespace, modelName
 	return nil
 }
 func (m *mockStore) TriggerResync(ctx context.Context, namespace, modelName string) error { return nil }
+func (m *mockStore) TriggerVersionResync(ctx context.Context, namespace, modelName, versionName string) error {
+	return nil
+}
 func (m *mockStore) CreateModelSource(ctx context.Context, namespace, name string, spec modelv1.ModelSourceSpec) error {
 	s := api.ModelSourceSummary{Name: name, Namespace: namespace, Type: spec.Type, SecretRef: spec.SecretRef, CredentialsReady: true, LastChecked: time.Now()}
 	m.sources[namespace+"/"+name] = s
@@ -118,7 +121,7 @@ func mockDetailFromSpec(ns, name string, spec modelv1.ModelSpec) api.ModelDetail
 		tags = append(tags, spec.Display.Tags...)
 		desc = spec.Display.Description
 	}
-	summary := api.ModelSummary{Name: name, Namespace: ns, SourceRef: spec.SourceRef, Tags: tags, VersionsReady: 0, VersionsTotal: len(versions), LastSyncTime: time.Now(), Status: api.PhasePending}
+	summary := api.ModelSummary{Name: name, Namespace: ns, SourceRef: spec.SourceRef.String(), Tags: tags, VersionsReady: 0, VersionsTotal: len(versions), LastSyncTime: time.Now(), Status: api.PhasePending}
 	return api.ModelDetail{Summary: summary, Description: desc, Versions: versions}
 }
 
@@ -18,6 +18,7 @@ type Store interface {
 	DeleteModelVersion(ctx context.Context, namespace, modelName, versionName string) error
 	ToggleVersionShare(ctx context.Context, namespace, modelName, versionName string, enabled bool) error
 	TriggerResync(ctx context.Context, namespace, modelName string) error
+	TriggerVersionResync(ctx context.Context, namespace, modelName, versionName string) error
 	CreateModelSource(ctx context.Context, namespace, name string, spec modelv1.ModelSourceSpec) error
 	UpdateModelSource(ctx context.Context, namespace, name string, spec modelv1.ModelSourceSpec) error
 	DeleteModelSource(ctx context.Context, namespace, name string) error
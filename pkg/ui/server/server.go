@@ -80,6 +80,7 @@ func (s *Server) Routes() http.Handler {
 		apiRouter.Delete("/models/{namespace}/{name}/versions/{version}", s.handleModelVersionDelete)
 		apiRouter.Post("/models/{namespace}/{name}/versions/{version}/share", s.handleShareToggle)
 		apiRouter.Post("/models/{namespace}/{name}/actions/resync", s.handleResync)
+		apiRouter.Post("/models/{namespace}/{name}/versions/{version}/actions/resync", s.handleVersionResync)
 		apiRouter.Get("/modelsources", s.handleModelSources)
 		apiRouter.Get("/modelsources/{namespace}/{name}", s.handleModelSourceDetail)
 		apiRouter.Post("/modelsources", s.handleModelSourceCreate)
@@ -295,6 +296,21 @@ func (s *Server) handleResync(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (s *Server) handleVersionResync(w http.ResponseWriter, r *http.Request) {
+	namespace := chi.URLParam(r, "namespace")
+	name := chi.URLParam(r, "name")
+	version := chi.URLParam(r, "version")
+	if version == "" {
+		writeError(w, http.StatusBadRequest, "version is required")
+		return
+	}
+	if err := s.store.TriggerVersionResync(r.Context(), namespace, name, version); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 type modelSourceCreateRequest struct {
 	Name      string            `json:"name"`
 	Namespace string            `json:"namespace"`
@@ -430,7 +446,7 @@ func toModelSpec(req modelCreateUpdateRequest) modelv1.ModelSpec {
 		versions = append(versions, mv)
 	}
 	disp := &modelv1.DisplaySpec{Description: req.Description, Tags: req.Tags}
-	return modelv1.ModelSpec{SourceRef: req.SourceRef, Display: disp, Versions: versions}
+	return modelv1.ModelSpec{SourceRef: modelv1.ParseSourceReference(req.SourceRef), Display: disp, Versions: versions}
 }
 
 func (s *Server) handleSecretValidate(w http.ResponseWriter, r *http.Request) {
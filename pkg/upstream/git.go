@@ -0,0 +1,129 @@
+package upstream
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// lsRemote resolves revision against a git repository over the smart HTTP protocol,
+// equivalent to `git ls-remote <repoURL> <revision>`.
+// Annotated tags resolve to the commit they point to.
+func lsRemote(ctx context.Context, client *http.Client, repoURL string, creds Credentials, revision string) (string, error) {
+	refs, err := listRemoteRefs(ctx, client, repoURL, creds)
+	if err != nil {
+		return "", err
+	}
+
+	candidates := []string{
+		"refs/tags/" + revision + "^{}",
+		"refs/tags/" + revision,
+		"refs/heads/" + revision,
+		revision,
+	}
+	for _, ref := range candidates {
+		if sha, ok := refs[ref]; ok {
+			return sha, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s@%s", ErrRevisionNotFound, repoURL, revision)
+}
+
+// listRemoteRefs fetches the ref advertisement of a repository and returns a ref name to SHA map.
+func listRemoteRefs(ctx context.Context, client *http.Client, repoURL string, creds Credentials) (map[string]string, error) {
+	infoURL := strings.TrimSuffix(repoURL, "/") + "/info/refs?service=git-upload-pack"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, infoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	setGitAuth(req, creds)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", infoURL, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("%w: %s returned %s", ErrUnauthorized, repoURL, resp.Status)
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrRepoNotFound, repoURL)
+	default:
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return nil, fmt.Errorf("%w: %s returned %s", ErrUnreachable, infoURL, resp.Status)
+		}
+		return nil, fmt.Errorf("%s returned %s", infoURL, resp.Status)
+	}
+
+	return parseRefAdvertisement(resp.Body)
+}
+
+// parseRefAdvertisement parses pkt-line encoded "<sha> <ref>" lines.
+func parseRefAdvertisement(r io.Reader) (map[string]string, error) {
+	refs := make(map[string]string)
+	reader := bufio.NewReader(r)
+	for {
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if err == io.EOF {
+				return refs, nil
+			}
+			return nil, fmt.Errorf("read pkt-line length: %w", err)
+		}
+		length, err := strconv.ParseUint(string(header), 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pkt-line length %q", header)
+		}
+		if length == 0 {
+			// flush-pkt separates the service header from the refs
+			continue
+		}
+		if length < 4 {
+			return nil, fmt.Errorf("invalid pkt-line length %d", length)
+		}
+
+		payload := make([]byte, length-4)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return nil, fmt.Errorf("read pkt-line: %w", err)
+		}
+
+		line := strings.TrimSuffix(string(payload), "\n")
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		// The first ref carries capabilities after a NUL byte
+		if idx := strings.IndexByte(line, 0); idx >= 0 {
+			line = line[:idx]
+		}
+		sha, ref, ok := strings.Cut(line, " ")
+		if ok && IsCommitSHA(sha) {
+			refs[ref] = sha
+		}
+	}
+}
+
+func setGitAuth(req *http.Request, creds Credentials) {
+	switch {
+	case creds.Username != "" && creds.Password != "":
+		req.SetBasicAuth(creds.Username, creds.Password)
+	case creds.Token != "":
+		req.SetBasicAuth("oauth2", creds.Token)
+	}
+}
+
+// checkGitRevision checks that a repository exists and, unless revision is a commit SHA
+// that the ref advertisement cannot confirm, that it has the revision.
+func checkGitRevision(ctx context.Context, client *http.Client, repoURL string, creds Credentials, revision string) error {
+	if IsCommitSHA(revision) {
+		_, err := listRemoteRefs(ctx, client, repoURL, creds)
+		return err
+	}
+	_, err := lsRemote(ctx, client, repoURL, creds, revision)
+	return err
+}
@@ -0,0 +1,97 @@
+package upstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// huggingFaceModelInfo is the subset of the HuggingFace model info API response used by modelfs.
+type huggingFaceModelInfo struct {
+	SHA string `json:"sha"`
+}
+
+// fetchHuggingFaceRevision calls GET {endpoint}/api/models/{repo}/revision/{revision}.
+func fetchHuggingFaceRevision(ctx context.Context, client *http.Client, endpoint string, creds Credentials, repo, revision string) (*huggingFaceModelInfo, error) {
+	apiURL := fmt.Sprintf("%s/api/models/%s/revision/%s", endpoint, repo, url.PathEscape(revision))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if creds.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+creds.Token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", apiURL, err)
+	}
+	defer resp.Body.Close()
+
+	if err := huggingFaceError(resp, repo, revision); err != nil {
+		return nil, err
+	}
+
+	info := &huggingFaceModelInfo{}
+	if err := json.NewDecoder(resp.Body).Decode(info); err != nil {
+		return nil, fmt.Errorf("decode model info for %s@%s: %w", repo, revision, err)
+	}
+	if info.SHA == "" {
+		return nil, fmt.Errorf("model info for %s@%s has no sha", repo, revision)
+	}
+	return info, nil
+}
+
+// huggingFaceError maps a non-2xx HuggingFace API response to an error.
+// HuggingFace reports the precise cause in the X-Error-Code header.
+func huggingFaceError(resp *http.Response, repo, revision string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	switch resp.Header.Get("X-Error-Code") {
+	case "GatedRepo":
+		return fmt.Errorf("%w: %s", ErrAccessGated, repo)
+	case "RepoNotFound":
+		return fmt.Errorf("%w: %s", ErrRepoNotFound, repo)
+	case "RevisionNotFound", "EntryNotFound":
+		return fmt.Errorf("%w: %s@%s", ErrRevisionNotFound, repo, revision)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w: %s returned %s", ErrUnauthorized, resp.Request.URL, resp.Status)
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrRepoNotFound, repo)
+	default:
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%w: %s returned %s", ErrUnreachable, resp.Request.URL, resp.Status)
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", resp.Request.URL, resp.Status, body)
+	}
+}
+
+// checkHuggingFaceAccess calls GET {endpoint}/api/models/{repo}/auth-check, which fails
+// for gated repos whose conditions the token owner has not accepted.
+func checkHuggingFaceAccess(ctx context.Context, client *http.Client, endpoint string, creds Credentials, repo string) error {
+	apiURL := fmt.Sprintf("%s/api/models/%s/auth-check", endpoint, repo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return err
+	}
+	if creds.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+creds.Token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("get %s: %w", apiURL, err)
+	}
+	defer resp.Body.Close()
+
+	return huggingFaceError(resp, repo, "")
+}
@@ -0,0 +1,194 @@
+package upstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	modelv1 "github.com/samzong/modelfs/api/v1"
+)
+
+// ErrNoProbeTarget is returned when a source has no endpoint that can be probed,
+// e.g. a GIT source whose repositories are only named by its Models.
+var ErrNoProbeTarget = errors.New("no endpoint to probe")
+
+// Prober checks that a source endpoint is reachable and accepts the credentials.
+// A nil error means both; ErrUnauthorized means the endpoint answered but rejected
+// the credentials; ErrUnreachable or a transport error means it could not be contacted.
+// Without credentials only reachability is checked.
+type Prober interface {
+	Probe(ctx context.Context, spec modelv1.ModelSourceSpec, creds Credentials) error
+}
+
+// ProberFunc adapts a function to a Prober.
+type ProberFunc func(ctx context.Context, spec modelv1.ModelSourceSpec, creds Credentials) error
+
+// Probe implements Prober.
+func (f ProberFunc) Probe(ctx context.Context, spec modelv1.ModelSourceSpec, creds Credentials) error {
+	return f(ctx, spec, creds)
+}
+
+// Probers maps a source type to its Prober. Types without an entry are not probed.
+type Probers map[string]Prober
+
+// NewHTTPProbers returns the built-in probers using client, or a client with a default timeout if nil:
+// HuggingFace and ModelScope whoami, S3 HeadBucket, HTTP HEAD and git ls-remote.
+// Every prober honors config["endpoint"], so it can be pointed at a local stand-in.
+func NewHTTPProbers(client *http.Client) Probers {
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	return Probers{
+		"HUGGING_FACE": ProberFunc(func(ctx context.Context, spec modelv1.ModelSourceSpec, creds Credentials) error {
+			return probeHuggingFace(ctx, client, spec, creds)
+		}),
+		"MODEL_SCOPE": ProberFunc(func(ctx context.Context, spec modelv1.ModelSourceSpec, creds Credentials) error {
+			return probeModelScope(ctx, client, spec, creds)
+		}),
+		"S3": ProberFunc(func(ctx context.Context, spec modelv1.ModelSourceSpec, creds Credentials) error {
+			return probeS3(ctx, client, spec, creds)
+		}),
+		"HTTP": ProberFunc(func(ctx context.Context, spec modelv1.ModelSourceSpec, creds Credentials) error {
+			return probeHTTP(ctx, client, spec, creds)
+		}),
+		"GIT": ProberFunc(func(ctx context.Context, spec modelv1.ModelSourceSpec, creds Credentials) error {
+			return probeGit(ctx, client, spec, creds)
+		}),
+	}
+}
+
+// probeHuggingFace calls GET {endpoint}/api/whoami-v2.
+func probeHuggingFace(ctx context.Context, client *http.Client, spec modelv1.ModelSourceSpec, creds Credentials) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, Endpoint(spec)+"/api/whoami-v2", nil)
+	if err != nil {
+		return err
+	}
+	if creds.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+creds.Token)
+	}
+	return probeReachable(client, req, creds.Token == "")
+}
+
+// modelScopeLoginResponse is the subset of the ModelScope login API response used by modelfs.
+type modelScopeLoginResponse struct {
+	Success bool   `json:"Success"`
+	Message string `json:"Message"`
+}
+
+// probeModelScope calls POST {endpoint}/api/v1/login with the access token,
+// which is how the ModelScope SDK checks a token.
+func probeModelScope(ctx context.Context, client *http.Client, spec modelv1.ModelSourceSpec, creds Credentials) error {
+	if creds.Token == "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, Endpoint(spec), nil)
+		if err != nil {
+			return err
+		}
+		return probeReachable(client, req, true)
+	}
+
+	body, err := json.Marshal(map[string]string{"AccessToken": creds.Token})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, Endpoint(spec)+"/api/v1/login", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := doRequest(client, req, false)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	login := &modelScopeLoginResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(login); err != nil {
+		return fmt.Errorf("decode login response from %s: %w", req.URL, err)
+	}
+	if !login.Success {
+		return fmt.Errorf("%w: %s rejected the token: %s", ErrUnauthorized, req.URL, login.Message)
+	}
+	return nil
+}
+
+// probeHTTP sends HEAD to config["endpoint"], falling back to config["uri"] or config["url"].
+func probeHTTP(ctx context.Context, client *http.Client, spec modelv1.ModelSourceSpec, creds Credentials) error {
+	target := probeTarget(spec)
+	if target == "" {
+		return ErrNoProbeTarget
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target, nil)
+	if err != nil {
+		return err
+	}
+	switch {
+	case creds.Username != "" && creds.Password != "":
+		req.SetBasicAuth(creds.Username, creds.Password)
+	case creds.Token != "":
+		req.Header.Set("Authorization", "Bearer "+creds.Token)
+	}
+	return probeReachable(client, req, false)
+}
+
+// probeGit lists the refs of config["endpoint"], falling back to config["uri"] or config["url"].
+// Only HTTP(S) remotes can be probed.
+func probeGit(ctx context.Context, client *http.Client, spec modelv1.ModelSourceSpec, creds Credentials) error {
+	target := probeTarget(spec)
+	if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+		return ErrNoProbeTarget
+	}
+	_, err := listRemoteRefs(ctx, client, target, creds)
+	return err
+}
+
+func probeTarget(spec modelv1.ModelSourceSpec) string {
+	for _, key := range []string{"endpoint", "uri", "url"} {
+		if target := spec.Config[key]; target != "" {
+			return target
+		}
+	}
+	return ""
+}
+
+// probeReachable sends req and discards the response body.
+func probeReachable(client *http.Client, req *http.Request, anonymous bool) error {
+	resp, err := doRequest(client, req, anonymous)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return err
+}
+
+// doRequest sends req and maps the response status to an error.
+// With anonymous set, 401 and 403 still count as reachable since no credentials were offered,
+// and a nil response is returned. The caller closes the body of a returned response.
+func doRequest(client *http.Client, req *http.Request, anonymous bool) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Redacted(), err)
+	}
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 400:
+		return resp, nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		resp.Body.Close()
+		if anonymous {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: %s returned %s", ErrUnauthorized, req.URL.Redacted(), resp.Status)
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrRepoNotFound, req.URL.Redacted())
+	case resp.StatusCode >= http.StatusInternalServerError:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s returned %s", ErrUnreachable, req.URL.Redacted(), resp.Status)
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s returned %s", req.URL.Redacted(), resp.Status)
+	}
+}
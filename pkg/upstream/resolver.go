@@ -0,0 +1,164 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	modelv1 "github.com/samzong/modelfs/api/v1"
+)
+
+const (
+	// DefaultHuggingFaceEndpoint is used when a HUGGING_FACE source does not set config["endpoint"].
+	DefaultHuggingFaceEndpoint = "https://huggingface.co"
+	// DefaultModelScopeEndpoint is used when a MODEL_SCOPE source does not set config["endpoint"].
+	DefaultModelScopeEndpoint = "https://www.modelscope.cn"
+
+	defaultRevision = "main"
+	defaultTimeout  = 30 * time.Second
+)
+
+var (
+	// ErrUnsupported is returned when a source type has no notion of revisions.
+	ErrUnsupported = errors.New("revision resolution not supported for source type")
+	// ErrRepoNotFound is returned when the repository does not exist or is not visible.
+	ErrRepoNotFound = errors.New("repository not found")
+	// ErrRevisionNotFound is returned when the repository exists but the revision does not.
+	ErrRevisionNotFound = errors.New("revision not found")
+	// ErrAccessGated is returned when the repository requires accepting its access conditions first.
+	ErrAccessGated = errors.New("access to repository is gated")
+	// ErrUnauthorized is returned when the source rejects the credentials.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrUnreachable is returned when the source could not be contacted or answered with a server error.
+	ErrUnreachable = errors.New("unreachable")
+
+	commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+// Credentials holds the subset of Secret data used to talk to a source API.
+type Credentials struct {
+	Token     string
+	Username  string
+	Password  string
+	AccessKey string
+	SecretKey string
+}
+
+// CredentialsFromSecretData extracts Credentials from Secret data using the
+// key names understood by the Dataset loader.
+func CredentialsFromSecretData(data map[string][]byte) Credentials {
+	return Credentials{
+		Token:     strings.TrimSpace(string(data["token"])),
+		Username:  strings.TrimSpace(string(data["username"])),
+		Password:  strings.TrimSpace(string(data["password"])),
+		AccessKey: strings.TrimSpace(string(data["access-key"])),
+		SecretKey: strings.TrimSpace(string(data["secret-key"])),
+	}
+}
+
+// Resolver resolves a floating revision (branch or tag) of a repository to an immutable commit SHA.
+type Resolver interface {
+	ResolveRevision(ctx context.Context, spec modelv1.ModelSourceSpec, creds Credentials, repo, revision string) (string, error)
+}
+
+// HTTPResolver resolves revisions through the HuggingFace API and the git smart HTTP protocol.
+// The endpoint is taken from the ModelSource config, so it can be pointed at a local stand-in.
+type HTTPResolver struct {
+	Client *http.Client
+}
+
+var _ Resolver = &HTTPResolver{}
+
+// NewHTTPResolver returns an HTTPResolver using client, or a client with a default timeout if nil.
+func NewHTTPResolver(client *http.Client) *HTTPResolver {
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	return &HTTPResolver{Client: client}
+}
+
+// ResolveRevision implements Resolver.
+func (r *HTTPResolver) ResolveRevision(ctx context.Context, spec modelv1.ModelSourceSpec, creds Credentials, repo, revision string) (string, error) {
+	if revision == "" {
+		revision = defaultRevision
+	}
+	if IsCommitSHA(revision) {
+		return revision, nil
+	}
+
+	switch spec.Type {
+	case "HUGGING_FACE":
+		info, err := fetchHuggingFaceRevision(ctx, r.Client, Endpoint(spec), creds, repo, revision)
+		if err != nil {
+			return "", err
+		}
+		return info.SHA, nil
+	case "MODEL_SCOPE":
+		return lsRemote(ctx, r.Client, Endpoint(spec)+"/"+repo+".git", creds, revision)
+	case "GIT":
+		return lsRemote(ctx, r.Client, repo, creds, revision)
+	default:
+		return "", ErrUnsupported
+	}
+}
+
+// Endpoint returns the API endpoint of a source, honoring config["endpoint"].
+func Endpoint(spec modelv1.ModelSourceSpec) string {
+	if endpoint := spec.Config["endpoint"]; endpoint != "" {
+		return strings.TrimSuffix(endpoint, "/")
+	}
+	switch spec.Type {
+	case "HUGGING_FACE":
+		return DefaultHuggingFaceEndpoint
+	case "MODEL_SCOPE":
+		return DefaultModelScopeEndpoint
+	default:
+		return ""
+	}
+}
+
+// IsUnreachable reports whether err means the source could not be contacted,
+// as opposed to the source answering with an error.
+func IsUnreachable(err error) bool {
+	var urlErr *url.Error
+	return errors.Is(err, ErrUnreachable) || errors.As(err, &urlErr)
+}
+
+// IsCommitSHA reports whether revision is a full 40-character commit SHA.
+func IsCommitSHA(revision string) bool {
+	return commitSHAPattern.MatchString(revision)
+}
+
+// Preflighter checks that a repository revision exists and can be downloaded with the credentials.
+type Preflighter interface {
+	CheckAccess(ctx context.Context, spec modelv1.ModelSourceSpec, creds Credentials, repo, revision string) error
+}
+
+var _ Preflighter = &HTTPResolver{}
+
+// CheckAccess implements Preflighter. It returns ErrRepoNotFound, ErrRevisionNotFound,
+// ErrAccessGated or ErrUnauthorized for the corresponding problems, and ErrUnsupported
+// for source types it cannot check.
+func (r *HTTPResolver) CheckAccess(ctx context.Context, spec modelv1.ModelSourceSpec, creds Credentials, repo, revision string) error {
+	if revision == "" {
+		revision = defaultRevision
+	}
+
+	switch spec.Type {
+	case "HUGGING_FACE":
+		if _, err := fetchHuggingFaceRevision(ctx, r.Client, Endpoint(spec), creds, repo, revision); err != nil {
+			return err
+		}
+		return checkHuggingFaceAccess(ctx, r.Client, Endpoint(spec), creds, repo)
+	case "MODEL_SCOPE":
+		return checkGitRevision(ctx, r.Client, Endpoint(spec)+"/"+repo+".git", creds, revision)
+	case "GIT":
+		return checkGitRevision(ctx, r.Client, repo, creds, revision)
+	default:
+		return ErrUnsupported
+	}
+}
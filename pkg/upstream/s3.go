@@ -0,0 +1,104 @@
+package upstream
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	modelv1 "github.com/samzong/modelfs/api/v1"
+)
+
+const (
+	defaultS3Region = "us-east-1"
+	// emptyPayloadHash is the hex SHA-256 of an empty request body.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// probeS3 sends HeadBucket for the bucket of config["uri"] or config["url"] (s3://bucket/prefix)
+// to config["endpoint"], or the AWS endpoint of config["region"]. The bucket is addressed
+// path-style so S3-compatible stores work without DNS for each bucket.
+func probeS3(ctx context.Context, client *http.Client, spec modelv1.ModelSourceSpec, creds Credentials) error {
+	bucket := s3Bucket(spec)
+	if bucket == "" {
+		return ErrNoProbeTarget
+	}
+	region := spec.Config["region"]
+	if region == "" {
+		region = defaultS3Region
+	}
+	endpoint := strings.TrimSuffix(spec.Config["endpoint"], "/")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint+"/"+url.PathEscape(bucket), nil)
+	if err != nil {
+		return err
+	}
+	anonymous := creds.AccessKey == "" || creds.SecretKey == ""
+	if !anonymous {
+		signS3Request(req, creds, region, time.Now().UTC())
+	}
+
+	return probeReachable(client, req, anonymous)
+}
+
+func s3Bucket(spec modelv1.ModelSourceSpec) string {
+	for _, key := range []string{"uri", "url"} {
+		u, err := url.Parse(spec.Config[key])
+		if err == nil && u.Scheme == "s3" && u.Host != "" {
+			return u.Host
+		}
+	}
+	return ""
+}
+
+// signS3Request signs a request without a body with AWS Signature Version 4.
+func signS3Request(req *http.Request, creds Credentials, region string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	scope := date + "/" + region + "/s3/aws4_request"
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + emptyPayloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,228 @@
+package upstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	modelv1 "github.com/samzong/modelfs/api/v1"
+)
+
+// RepoFile is a file in a repository listing.
+type RepoFile struct {
+	Path string
+	Size int64
+}
+
+// Sizer lists the files of a repository revision to estimate how much storage a sync needs.
+type Sizer interface {
+	ListFiles(ctx context.Context, spec modelv1.ModelSourceSpec, creds Credentials, repo, revision string) ([]RepoFile, error)
+}
+
+var _ Sizer = &HTTPResolver{}
+
+// ListFiles implements Sizer for HUGGING_FACE and MODEL_SCOPE sources, and returns
+// ErrUnsupported for other types.
+func (r *HTTPResolver) ListFiles(ctx context.Context, spec modelv1.ModelSourceSpec, creds Credentials, repo, revision string) ([]RepoFile, error) {
+	if revision == "" {
+		revision = defaultRevision
+	}
+
+	switch spec.Type {
+	case "HUGGING_FACE":
+		return listHuggingFaceFiles(ctx, r.Client, Endpoint(spec), creds, repo, revision)
+	case "MODEL_SCOPE":
+		return listModelScopeFiles(ctx, r.Client, Endpoint(spec), creds, repo, revision)
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+// FilteredSize sums the sizes of the files that the include and exclude patterns of the
+// source config select, the way the Dataset loader downloads them. Patterns are separated
+// by commas; "*" also matches "/".
+func FilteredSize(spec modelv1.ModelSourceSpec, files []RepoFile) int64 {
+	include := splitPatterns(spec.Config["include"])
+	exclude := splitPatterns(spec.Config["exclude"])
+
+	var total int64
+	for _, file := range files {
+		if len(include) > 0 && !matchAny(include, file.Path) {
+			continue
+		}
+		if matchAny(exclude, file.Path) {
+			continue
+		}
+		total += file.Size
+	}
+	return total
+}
+
+// huggingFaceTreeEntry is an entry of the HuggingFace tree API response.
+type huggingFaceTreeEntry struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	LFS  *struct {
+		Size int64 `json:"size"`
+	} `json:"lfs,omitempty"`
+}
+
+// listHuggingFaceFiles calls GET {endpoint}/api/models/{repo}/tree/{revision}?recursive=true,
+// following the Link header across pages.
+func listHuggingFaceFiles(ctx context.Context, client *http.Client, endpoint string, creds Credentials, repo, revision string) ([]RepoFile, error) {
+	next := fmt.Sprintf("%s/api/models/%s/tree/%s?recursive=true", endpoint, repo, url.PathEscape(revision))
+	var files []RepoFile
+	for next != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return nil, err
+		}
+		if creds.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+creds.Token)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("get %s: %w", next, err)
+		}
+		if err := huggingFaceError(resp, repo, revision); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var entries []huggingFaceTreeEntry
+		err = json.NewDecoder(resp.Body).Decode(&entries)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode file listing for %s@%s: %w", repo, revision, err)
+		}
+		for _, entry := range entries {
+			if entry.Type != "file" {
+				continue
+			}
+			size := entry.Size
+			if entry.LFS != nil {
+				size = entry.LFS.Size
+			}
+			files = append(files, RepoFile{Path: entry.Path, Size: size})
+		}
+		next = nextLink(resp.Header.Get("Link"))
+	}
+	return files, nil
+}
+
+// modelScopeFilesResponse is the subset of the ModelScope repo files API response used by modelfs.
+type modelScopeFilesResponse struct {
+	Code    int    `json:"Code"`
+	Message string `json:"Message"`
+	Data    struct {
+		Files []struct {
+			Type string `json:"Type"`
+			Path string `json:"Path"`
+			Size int64  `json:"Size"`
+		} `json:"Files"`
+	} `json:"Data"`
+}
+
+// listModelScopeFiles calls GET {endpoint}/api/v1/models/{repo}/repo/files?Revision={revision}&Recursive=true.
+func listModelScopeFiles(ctx context.Context, client *http.Client, endpoint string, creds Credentials, repo, revision string) ([]RepoFile, error) {
+	apiURL := fmt.Sprintf("%s/api/v1/models/%s/repo/files?Revision=%s&Recursive=true", endpoint, repo, url.QueryEscape(revision))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if creds.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+creds.Token)
+	}
+
+	resp, err := doRequest(client, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	listing := &modelScopeFilesResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(listing); err != nil {
+		return nil, fmt.Errorf("decode file listing for %s@%s: %w", repo, revision, err)
+	}
+	if listing.Code != 0 && listing.Code != http.StatusOK {
+		return nil, fmt.Errorf("list files of %s@%s: %s", repo, revision, listing.Message)
+	}
+
+	var files []RepoFile
+	for _, file := range listing.Data.Files {
+		if file.Type == "tree" {
+			continue
+		}
+		files = append(files, RepoFile{Path: file.Path, Size: file.Size})
+	}
+	return files, nil
+}
+
+var linkNextPattern = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// nextLink returns the rel="next" URL of a Link header, or "".
+func nextLink(header string) string {
+	if match := linkNextPattern.FindStringSubmatch(header); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+func splitPatterns(value string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if globPattern(pattern).MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// globPattern compiles a shell pattern with fnmatch semantics, where "*" also matches "/".
+func globPattern(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return regexp.MustCompile("^" + regexp.QuoteMeta(pattern) + "$")
+	}
+	return re
+}
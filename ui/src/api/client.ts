@@ -53,4 +53,7 @@ export const client = {
   async triggerResync(ns: string, name: string): Promise<void> {
     await apiFetch(`/api/models/${encodeURIComponent(ns)}/${encodeURIComponent(name)}/actions/resync`, { method: "POST" });
   },
+  async triggerVersionResync(ns: string, name: string, version: string): Promise<void> {
+    await apiFetch(`/api/models/${encodeURIComponent(ns)}/${encodeURIComponent(name)}/versions/${encodeURIComponent(version)}/actions/resync`, { method: "POST" });
+  },
 };
@@ -16,6 +16,7 @@ export interface ModelVersionView {
   name: string;
   repo: string;
   revision?: string;
+  resolvedRevision?: string;
   precision?: string;
   desiredState: string;
   shareEnabled: boolean;
@@ -26,12 +27,23 @@ export interface ModelVersionView {
   observedStorage?: string;
 }
 
+export interface ShareTargetView {
+  version: string;
+  namespace: string;
+  dataset?: string;
+  pvcName?: string;
+  phase: Phase;
+  state?: string;
+  expiresAt?: string;
+  lastError?: string;
+}
+
 export interface ModelDetail {
   summary: ModelSummary;
   description?: string;
   logoURL?: string;
   versions: ModelVersionView[];
-  shareTargets?: string[];
+  shareTargets?: ShareTargetView[];
   conditionsJson?: string;
 }
 
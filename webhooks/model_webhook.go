@@ -0,0 +1,210 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	"github.com/samzong/modelfs/pkg/config"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// MinUpdateCheckInterval is the shortest accepted upstream update check interval.
+	MinUpdateCheckInterval = time.Minute
+)
+
+// SetupModelWebhookWithManager registers the Model defaulting and validating webhooks.
+// Defaults are read from store, which follows the ModelfsConfig.
+func SetupModelWebhookWithManager(mgr ctrl.Manager, store *config.Store) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&modelv1.Model{}).
+		WithDefaulter(&ModelCustomDefaulter{Config: store}).
+		WithValidator(&ModelCustomValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-model-samzong-dev-v1-model,mutating=true,failurePolicy=fail,sideEffects=None,groups=model.samzong.dev,resources=models,verbs=create;update,versions=v1,name=mmodel.model.samzong.dev,admissionReviewVersions=v1
+
+// ModelCustomDefaulter sets default values on Model versions.
+type ModelCustomDefaulter struct {
+	// Config supplies the default revision. Built-in defaults are used when nil.
+	Config *config.Store
+}
+
+var _ admission.CustomDefaulter = &ModelCustomDefaulter{}
+
+// Default implements admission.CustomDefaulter.
+func (d *ModelCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	model, ok := obj.(*modelv1.Model)
+	if !ok {
+		return fmt.Errorf("expected a Model but got %T", obj)
+	}
+
+	// The revision is only defaulted on create, so later writes to a Model do not pin versions
+	// that follow the configured default
+	create := true
+	if req, err := admission.RequestFromContext(ctx); err == nil {
+		create = req.Operation == admissionv1.Create
+	}
+	if model.Spec.SourceRef.Kind == "" {
+		model.Spec.SourceRef.Kind = modelv1.SourceKindModelSource
+	}
+	defaults := d.Config.For(model.Namespace)
+	for i := range model.Spec.Versions {
+		version := &model.Spec.Versions[i]
+		if version.State == "" {
+			version.State = modelv1.ModelVersionStatePresent
+		}
+		if create && version.Revision == "" {
+			version.Revision = defaults.Revision
+		}
+	}
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-model-samzong-dev-v1-model,mutating=false,failurePolicy=fail,sideEffects=None,groups=model.samzong.dev,resources=models,verbs=create;update,versions=v1,name=vmodel.model.samzong.dev,admissionReviewVersions=v1
+
+// ModelCustomValidator validates Model versions on create and update.
+type ModelCustomValidator struct{}
+
+var _ admission.CustomValidator = &ModelCustomValidator{}
+
+// ValidateCreate implements admission.CustomValidator.
+func (v *ModelCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	model, ok := obj.(*modelv1.Model)
+	if !ok {
+		return nil, fmt.Errorf("expected a Model but got %T", obj)
+	}
+	return nil, invalidError("Model", model.Name, validateModel(model, nil))
+}
+
+// ValidateUpdate implements admission.CustomValidator.
+func (v *ModelCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldModel, ok := oldObj.(*modelv1.Model)
+	if !ok {
+		return nil, fmt.Errorf("expected a Model but got %T", oldObj)
+	}
+	model, ok := newObj.(*modelv1.Model)
+	if !ok {
+		return nil, fmt.Errorf("expected a Model but got %T", newObj)
+	}
+
+	allErrs := validateModel(model, oldModel)
+	allErrs = append(allErrs, validateVersionRepoImmutable(oldModel, model)...)
+	return nil, invalidError("Model", model.Name, allErrs)
+}
+
+// ValidateDelete implements admission.CustomValidator.
+func (v *ModelCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateModel validates a Model; oldModel is the stored Model on update and nil on create.
+func validateModel(model, oldModel *modelv1.Model) field.ErrorList {
+	var allErrs field.ErrorList
+	versionsPath := field.NewPath("spec", "versions")
+
+	if len(model.Spec.Versions) == 0 {
+		allErrs = append(allErrs, field.Required(versionsPath, "at least one version is required"))
+	}
+
+	// The CRD schema accepts any sourceRef so that the legacy string form stays readable
+	sourceRefPath := field.NewPath("spec", "sourceRef")
+	if model.Spec.SourceRef.Name == "" {
+		allErrs = append(allErrs, field.Required(sourceRefPath.Child("name"), "a source name is required"))
+	}
+	switch model.Spec.SourceRef.Kind {
+	case "", modelv1.SourceKindModelSource, modelv1.SourceKindClusterModelSource:
+	default:
+		allErrs = append(allErrs, field.NotSupported(sourceRefPath.Child("kind"), model.Spec.SourceRef.Kind,
+			[]string{modelv1.SourceKindModelSource, modelv1.SourceKindClusterModelSource}))
+	}
+
+	seen := make(map[string]bool, len(model.Spec.Versions))
+	for i, version := range model.Spec.Versions {
+		namePath := versionsPath.Index(i).Child("name")
+		if seen[version.Name] {
+			allErrs = append(allErrs, field.Duplicate(namePath, version.Name))
+			continue
+		}
+		seen[version.Name] = true
+
+		if msgs := validation.IsDNS1123Label(version.Name); len(msgs) > 0 {
+			for _, msg := range msgs {
+				allErrs = append(allErrs, field.Invalid(namePath, version.Name, msg))
+			}
+			continue
+		}
+		// A version stored before its annotation keys were checked is kept, so the Model can still be updated
+		if !hasVersion(oldModel, version.Name) {
+			if errs := validateVersionAnnotationKeys(namePath, version.Name); len(errs) > 0 {
+				allErrs = append(allErrs, errs...)
+				continue
+			}
+		}
+
+		if policy := version.UpdatePolicy; policy != nil && policy.Interval != nil && policy.Interval.Duration < MinUpdateCheckInterval {
+			allErrs = append(allErrs, field.Invalid(versionsPath.Index(i).Child("updatePolicy", "interval"), policy.Interval.Duration.String(),
+				fmt.Sprintf("must be at least %s", MinUpdateCheckInterval)))
+		}
+
+		if share := version.Share; share != nil && share.ExpiresAfter != nil && share.ExpiresAfter.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(versionsPath.Index(i).Child("share", "expiresAfter"), share.ExpiresAfter.Duration.String(),
+				"must be positive"))
+		}
+	}
+
+	return allErrs
+}
+
+// hasVersion reports whether model, which may be nil, has a version named versionName.
+func hasVersion(model *modelv1.Model, versionName string) bool {
+	if model == nil {
+		return false
+	}
+	for _, version := range model.Spec.Versions {
+		if version.Name == versionName {
+			return true
+		}
+	}
+	return false
+}
+
+// versionAnnotationPrefixes are the prefixes of the annotation keys that end with a version name.
+var versionAnnotationPrefixes = []string{modelv1.VersionResyncAnnotationPrefix, modelv1.ShareApprovalAnnotationPrefix}
+
+// validateVersionAnnotationKeys checks that a version name fits into the keys of its
+// annotations, whose name part is limited to 63 characters.
+func validateVersionAnnotationKeys(namePath *field.Path, versionName string) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, prefix := range versionAnnotationPrefixes {
+		for _, msg := range validation.IsQualifiedName(prefix + versionName) {
+			allErrs = append(allErrs, field.Invalid(namePath, versionName, fmt.Sprintf("annotation key %s%s: %s", prefix, versionName, msg)))
+		}
+	}
+	return allErrs
+}
+
+func validateVersionRepoImmutable(oldModel, model *modelv1.Model) field.ErrorList {
+	oldRepos := make(map[string]string, len(oldModel.Spec.Versions))
+	for _, version := range oldModel.Spec.Versions {
+		oldRepos[version.Name] = version.Repo
+	}
+
+	var allErrs field.ErrorList
+	for i, version := range model.Spec.Versions {
+		oldRepo, ok := oldRepos[version.Name]
+		if ok && oldRepo != version.Repo {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "versions").Index(i).Child("repo"),
+				fmt.Sprintf("repo of version %q is immutable (was %q); add a new version instead", version.Name, oldRepo)))
+		}
+	}
+	return allErrs
+}
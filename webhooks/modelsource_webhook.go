@@ -0,0 +1,162 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	"github.com/samzong/modelfs/pkg/dataset"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupModelSourceWebhookWithManager registers the ModelSource validating webhook.
+func SetupModelSourceWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&modelv1.ModelSource{}).
+		WithValidator(&ModelSourceCustomValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-model-samzong-dev-v1-modelsource,mutating=false,failurePolicy=fail,sideEffects=None,groups=model.samzong.dev,resources=modelsources,verbs=create;update,versions=v1,name=vmodelsource.model.samzong.dev,admissionReviewVersions=v1
+
+// ModelSourceCustomValidator validates ModelSource config keys against the source type.
+type ModelSourceCustomValidator struct{}
+
+var _ admission.CustomValidator = &ModelSourceCustomValidator{}
+
+// ValidateCreate implements admission.CustomValidator.
+func (v *ModelSourceCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	source, ok := obj.(*modelv1.ModelSource)
+	if !ok {
+		return nil, fmt.Errorf("expected a ModelSource but got %T", obj)
+	}
+	return nil, invalidError("ModelSource", source.Name, validateModelSourceSpec(&source.Spec, field.NewPath("spec")))
+}
+
+// ValidateUpdate implements admission.CustomValidator.
+func (v *ModelSourceCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	source, ok := newObj.(*modelv1.ModelSource)
+	if !ok {
+		return nil, fmt.Errorf("expected a ModelSource but got %T", newObj)
+	}
+	return nil, invalidError("ModelSource", source.Name, validateModelSourceSpec(&source.Spec, field.NewPath("spec")))
+}
+
+// ValidateDelete implements admission.CustomValidator.
+func (v *ModelSourceCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateModelSourceSpec(spec *modelv1.ModelSourceSpec, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	supported := dataset.SupportedConfigKeys(spec.Type)
+	if supported == nil {
+		return append(allErrs, field.Invalid(specPath.Child("type"), spec.Type, "unsupported source type"))
+	}
+
+	allowed := make(map[string]bool, len(supported))
+	for _, key := range supported {
+		allowed[key] = true
+	}
+
+	keys := make([]string, 0, len(spec.Config))
+	for key := range spec.Config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !allowed[key] {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("config").Key(key), spec.Config[key],
+				fmt.Sprintf("key is not supported for type %s (supported: %s)", spec.Type, strings.Join(supported, ", "))))
+		}
+	}
+
+	return append(allErrs, validateSecretKeys(spec, specPath)...)
+}
+
+func validateSecretKeys(spec *modelv1.ModelSourceSpec, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(spec.SecretKeys) == 0 {
+		return allErrs
+	}
+
+	secretKeysPath := specPath.Child("secretKeys")
+	if spec.SecretRef == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("secretRef"), "secretRef is required when secretKeys is set"))
+	}
+
+	options := dataset.SecretOptionNames(spec.Type)
+	allowed := make(map[string]bool, len(options))
+	for _, option := range options {
+		allowed[option] = true
+	}
+
+	keys := make([]string, 0, len(spec.SecretKeys))
+	for key := range spec.SecretKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	targets := make(map[string]string, len(keys))
+	for _, key := range keys {
+		option := spec.SecretKeys[key]
+		if !allowed[option] {
+			allErrs = append(allErrs, field.Invalid(secretKeysPath.Key(key), option,
+				fmt.Sprintf("option is not supported for type %s (supported: %s)", spec.Type, strings.Join(options, ", "))))
+			continue
+		}
+		if previous, ok := targets[option]; ok {
+			allErrs = append(allErrs, field.Invalid(secretKeysPath.Key(key), option,
+				fmt.Sprintf("option is already mapped from secret key %q", previous)))
+			continue
+		}
+		targets[option] = key
+	}
+
+	return allErrs
+}
+
+// SetupClusterModelSourceWebhookWithManager registers the ClusterModelSource validating webhook.
+func SetupClusterModelSourceWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&modelv1.ClusterModelSource{}).
+		WithValidator(&ClusterModelSourceCustomValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-model-samzong-dev-v1-clustermodelsource,mutating=false,failurePolicy=fail,sideEffects=None,groups=model.samzong.dev,resources=clustermodelsources,verbs=create;update,versions=v1,name=vclustermodelsource.model.samzong.dev,admissionReviewVersions=v1
+
+// ClusterModelSourceCustomValidator validates ClusterModelSource config keys against the source type.
+type ClusterModelSourceCustomValidator struct{}
+
+var _ admission.CustomValidator = &ClusterModelSourceCustomValidator{}
+
+// ValidateCreate implements admission.CustomValidator.
+func (v *ClusterModelSourceCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	source, ok := obj.(*modelv1.ClusterModelSource)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterModelSource but got %T", obj)
+	}
+	return nil, invalidError("ClusterModelSource", source.Name, validateModelSourceSpec(&source.Spec, field.NewPath("spec")))
+}
+
+// ValidateUpdate implements admission.CustomValidator.
+func (v *ClusterModelSourceCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	source, ok := newObj.(*modelv1.ClusterModelSource)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterModelSource but got %T", newObj)
+	}
+	return nil, invalidError("ClusterModelSource", source.Name, validateModelSourceSpec(&source.Spec, field.NewPath("spec")))
+}
+
+// ValidateDelete implements admission.CustomValidator.
+func (v *ClusterModelSourceCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
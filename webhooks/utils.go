@@ -0,0 +1,16 @@
+package webhooks
+
+import (
+	modelv1 "github.com/samzong/modelfs/api/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// invalidError wraps field errors into an Invalid API error for the given kind.
+// It returns nil when there are no errors.
+func invalidError(kind, name string, allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(modelv1.GroupVersion.WithKind(kind).GroupKind(), name, allErrs)
+}